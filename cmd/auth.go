@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"go.octolab.org/unsafe"
+
+	"github.com/kamilsk/check/http/availability"
+)
+
+// Environment variables used if credentials are not passed by flags.
+const (
+	basicAuthEnv   = "CHECK_BASIC_AUTH"
+	bearerTokenEnv = "CHECK_BEARER_TOKEN"
+)
+
+// credentials returns headers and authentication of requests to the website
+// and to additional hosts specified by the flags.
+func credentials(flags *pflag.FlagSet) ([]availability.Credential, error) {
+	headers, err := flags.GetStringArray("header")
+	if err != nil {
+		return nil, err
+	}
+	hosts, err := flags.GetStringArray("auth-host")
+	if err != nil {
+		return nil, err
+	}
+	var credential availability.Credential
+	if len(headers) > 0 {
+		credential.Header = make(http.Header, len(headers))
+		for _, header := range headers {
+			i := strings.Index(header, ":")
+			if i < 1 {
+				return nil, fmt.Errorf("invalid header %q: expected 'Name: value'", header)
+			}
+			name := textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(header[:i]))
+			credential.Header.Add(name, strings.TrimSpace(header[i+1:]))
+		}
+	}
+	if auth := flagOrEnv(flags, "basic-auth", basicAuthEnv); auth != "" {
+		i := strings.Index(auth, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid basic auth: expected 'user:password'")
+		}
+		credential.Username, credential.Password = auth[:i], auth[i+1:]
+	}
+	credential.Token = flagOrEnv(flags, "bearer-token", bearerTokenEnv)
+	if credential.Header == nil && credential.Username == "" && credential.Password == "" && credential.Token == "" {
+		return nil, nil
+	}
+	credentials := []availability.Credential{credential}
+	for _, host := range hosts {
+		credential.Host = host
+		credentials = append(credentials, credential)
+	}
+	return credentials, nil
+}
+
+// cookies returns cookies loaded from the file specified by the flag
+// or nil if it's not specified.
+func cookies(flags *pflag.FlagSet) (http.CookieJar, error) {
+	path := flags.Lookup("cookies").Value.String()
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { unsafe.Ignore(file.Close()) }()
+	return availability.LoadCookies(file)
+}
+
+func flagOrEnv(flags *pflag.FlagSet, name, env string) string {
+	if value := flags.Lookup(name).Value.String(); value != "" {
+		return value
+	}
+	return os.Getenv(env)
+}
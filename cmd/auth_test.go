@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"go.octolab.org/unsafe"
+
+	"github.com/kamilsk/check/http/availability"
+)
+
+func Test_credentials(t *testing.T) {
+	flags := func(args ...string) *pflag.FlagSet {
+		flags := pflag.NewFlagSet("urls", pflag.ContinueOnError)
+		urlsFlags(flags)
+		assert.NoError(t, flags.Parse(args))
+		return flags
+	}
+
+	obtained, err := credentials(flags())
+	assert.NoError(t, err)
+	assert.Empty(t, obtained)
+
+	obtained, err = credentials(flags(
+		"--header", "x-api-key: key",
+		"--basic-auth", "user:pass:word",
+		"--auth-host", "cdn.example.com",
+	))
+	assert.NoError(t, err)
+	expected := availability.Credential{
+		Header:   http.Header{"X-Api-Key": {"key"}},
+		Username: "user",
+		Password: "pass:word",
+	}
+	cdn := expected
+	cdn.Host = "cdn.example.com"
+	assert.Equal(t, []availability.Credential{expected, cdn}, obtained)
+
+	assert.NoError(t, os.Setenv(bearerTokenEnv, "token"))
+	defer func() { unsafe.Ignore(os.Unsetenv(bearerTokenEnv)) }()
+	obtained, err = credentials(flags())
+	assert.NoError(t, err)
+	assert.Equal(t, []availability.Credential{{Token: "token"}}, obtained)
+
+	_, err = credentials(flags("--header", "X-Api-Key"))
+	assert.Error(t, err)
+	_, err = credentials(flags("--basic-auth", "user"))
+	assert.Error(t, err)
+}
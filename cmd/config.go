@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	configFile = ".check.yml"
+	configFlag = "config"
+	siteURLKey = "url"
+)
+
+// globalOptions can't be specified for a website,
+// they configure the printer and the exit policy of the whole report.
+var globalOptions = map[string]bool{
+	"baseline":        true,
+	"decode":          true,
+	"fail-on":         true,
+	"format":          true,
+	"group-by":        true,
+	"hide":            true,
+	"ignore":          true,
+	"kind":            true,
+	"no-color":        true,
+	"no-error":        true,
+	"no-redirect":     true,
+	"only":            true,
+	"output":          true,
+	"show-attempts":   true,
+	"update-baseline": true,
+}
+
+// config contains default options and profiles of websites.
+// Options are named after flags of the command, e.g.
+//
+//	defaults:
+//	  concurrency: 4
+//	  exclude: ["*/logout*"]
+//	  fail-on: [4xx, 5xx, error]
+//	sites:
+//	  - url: https://kamil.samigullin.info/
+//	    max-depth: 3
+//	  - url: https://www.octolab.org/
+//	    external: skip
+//
+// Options of a website override the default ones
+// and flags passed to the command override both of them.
+// Printer options and the exit policy are global.
+type config struct {
+	Defaults map[string]interface{}   `yaml:"defaults"`
+	Sites    []map[string]interface{} `yaml:"sites"`
+}
+
+// loadConfig reads the config file specified by the flag
+// or the one found in the working directory.
+// An empty config is returned if the file is not specified and not found.
+func loadConfig(cmd *cobra.Command) (*config, error) {
+	path := cmd.Flag(configFlag).Value.String()
+	specified := path != ""
+	if !specified {
+		path = configFile
+	}
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		if !specified && os.IsNotExist(err) {
+			return &config{}, nil
+		}
+		return nil, err
+	}
+	cnf := &config{}
+	if err = yaml.UnmarshalStrict(blob, cnf); err != nil {
+		return nil, fmt.Errorf("parse config file %q: %v", path, err)
+	}
+	if err = cnf.validate(cmd); err != nil {
+		return nil, fmt.Errorf("invalid config file %q: %v", path, err)
+	}
+	return cnf, nil
+}
+
+// urls returns URLs of websites specified by the config.
+func (cnf *config) urls() []string {
+	urls := make([]string, 0, len(cnf.Sites))
+	for _, site := range cnf.Sites {
+		urls = append(urls, fmt.Sprint(site[siteURLKey]))
+	}
+	return urls
+}
+
+// profile returns options of the website with the passed URL
+// and false if they are not specified.
+func (cnf *config) profile(url string) (map[string]interface{}, bool) {
+	for _, site := range cnf.Sites {
+		if fmt.Sprint(site[siteURLKey]) == url && len(site) > 1 {
+			return site, true
+		}
+	}
+	return nil, false
+}
+
+// flags returns flags of the command combined with the default options
+// and options of the website. Flags changed by a user are not overridden.
+func (cnf *config) flags(cmd *cobra.Command, site map[string]interface{}) (*pflag.FlagSet, error) {
+	flags := pflag.NewFlagSet(cmd.Name(), pflag.ContinueOnError)
+	urlsFlags(flags)
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if target := flags.Lookup(flag.Name); target != nil && err == nil {
+			if slice, is := flag.Value.(pflag.SliceValue); is {
+				err = target.Value.(pflag.SliceValue).Replace(slice.GetSlice())
+				return
+			}
+			err = target.Value.Set(flag.Value.String())
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, options := range []map[string]interface{}{cnf.Defaults, site} {
+		for name, value := range options {
+			if name == siteURLKey || cmd.Flags().Changed(name) {
+				continue
+			}
+			if err = setOption(flags.Lookup(name), value); err != nil {
+				return nil, fmt.Errorf("invalid option %q: %v", name, err)
+			}
+		}
+	}
+	return flags, nil
+}
+
+func (cnf *config) validate(cmd *cobra.Command) error {
+	isOption := func(name string) bool {
+		return name != configFlag && cmd.Flags().Lookup(name) != nil
+	}
+	for name := range cnf.Defaults {
+		if !isOption(name) {
+			return fmt.Errorf("unknown option %q", name)
+		}
+	}
+	for i, site := range cnf.Sites {
+		if url, present := site[siteURLKey]; !present || fmt.Sprint(url) == "" {
+			return fmt.Errorf("site #%d has no url", i+1)
+		}
+		for name := range site {
+			if name == siteURLKey {
+				continue
+			}
+			if !isOption(name) {
+				return fmt.Errorf("unknown option %q of site #%d", name, i+1)
+			}
+			if globalOptions[name] {
+				return fmt.Errorf("option %q of site #%d can be specified only by defaults", name, i+1)
+			}
+		}
+	}
+	return nil
+}
+
+func setOption(flag *pflag.Flag, value interface{}) error {
+	slice, isSlice := flag.Value.(pflag.SliceValue)
+	list, isList := value.([]interface{})
+	switch {
+	case isSlice && isList:
+		values := make([]string, 0, len(list))
+		for _, item := range list {
+			values = append(values, fmt.Sprint(item))
+		}
+		return slice.Replace(values)
+	case isSlice:
+		return slice.Replace([]string{fmt.Sprint(value)})
+	case isList:
+		return fmt.Errorf("list is not expected")
+	}
+	return flag.Value.Set(fmt.Sprint(value))
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"go.octolab.org/unsafe"
+)
+
+func TestConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "check")
+	assert.NoError(t, err)
+	defer func() { unsafe.Ignore(os.RemoveAll(dir)) }()
+	file := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	command := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "urls"}
+		urlsFlags(cmd.Flags())
+		return cmd
+	}
+
+	t.Run("precedence", func(t *testing.T) {
+		cmd := command()
+		assert.NoError(t, cmd.Flags().Set(configFlag, file("precedence.yml", `
+defaults:
+  max-depth: 2
+  max-pages: 10
+  exclude: ["*/logout*", "re:[?&]date="]
+sites:
+  - url: https://kamil.samigullin.info/
+    max-depth: 3
+    max-pages: 20
+  - url: https://www.octolab.org/
+`)))
+		assert.NoError(t, cmd.Flags().Set("max-pages", "30"))
+		cnf, err := loadConfig(cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"https://kamil.samigullin.info/", "https://www.octolab.org/"}, cnf.urls())
+
+		flags, err := cnf.flags(cmd, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "2", flags.Lookup("max-depth").Value.String())
+		assert.Equal(t, "30", flags.Lookup("max-pages").Value.String())
+		exclude, err := flags.GetStringArray("exclude")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"*/logout*", "re:[?&]date="}, exclude)
+
+		site, specified := cnf.profile("https://kamil.samigullin.info/")
+		assert.True(t, specified)
+		flags, err = cnf.flags(cmd, site)
+		assert.NoError(t, err)
+		assert.Equal(t, "3", flags.Lookup("max-depth").Value.String())
+		assert.Equal(t, "30", flags.Lookup("max-pages").Value.String())
+
+		_, specified = cnf.profile("https://www.octolab.org/")
+		assert.False(t, specified)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		cnf, err := loadConfig(command())
+		assert.NoError(t, err)
+		assert.Empty(t, cnf.urls())
+
+		cmd := command()
+		assert.NoError(t, cmd.Flags().Set(configFlag, filepath.Join(dir, "unknown.yml")))
+		_, err = loadConfig(cmd)
+		assert.Error(t, err)
+	})
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"unknown field", "options: {}"},
+		{"unknown option", "defaults: {depth: 1}"},
+		{"unknown option of site", "sites: [{url: 'https://octolab.org/', depth: 1}]"},
+		{"global option of site", "sites: [{url: 'https://octolab.org/', format: json}]"},
+		{"site without url", "sites: [{max-depth: 1}]"},
+	}
+	for i, test := range tests {
+		tc := test
+		path := file(fmt.Sprintf("invalid-%d.yml", i), tc.content)
+		t.Run(test.name, func(t *testing.T) {
+			cmd := command()
+			assert.NoError(t, cmd.Flags().Set(configFlag, path))
+			_, err := loadConfig(cmd)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestURLs_config(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	cmd := urlsCmd
+	cmd.SetOutput(buf)
+	defer cmd.SetOutput(nil)
+	site, closer := site()
+	defer closer()
+
+	dir, err := ioutil.TempDir("", "check")
+	assert.NoError(t, err)
+	defer func() { unsafe.Ignore(os.RemoveAll(dir)) }()
+	path := filepath.Join(dir, configFile)
+	assert.NoError(t, ioutil.WriteFile(path, []byte(fmt.Sprintf(`
+defaults:
+  fail-on: [redirect]
+sites:
+  - url: %s/
+    retries: 1
+`, site.URL)), 0644))
+
+	config := cmd.Flag(configFlag)
+	unsafe.Ignore(config.Value.Set(path))
+	defer func() { unsafe.Ignore(config.Value.Set(config.DefValue)) }()
+	assert.NoError(t, cmd.RunE(cmd, nil))
+	assert.Contains(t, buf.String(), fmt.Sprintf("[200] %s/", site.URL))
+
+	unsafe.Ignore(config.Value.Set(config.DefValue))
+	assert.Error(t, cmd.RunE(cmd, nil))
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.octolab.org/unsafe"
+
+	"github.com/kamilsk/check/http/availability"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff old.json new.json",
+	Short: "Compare two reports of the urls command in the JSON format",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		before, err := loadSites(args[0])
+		if err != nil {
+			return err
+		}
+		after, err := loadSites(args[1])
+		if err != nil {
+			return err
+		}
+		return availability.
+			NewPrinter(
+				availability.ColorizeOutput(!asBool(cmd.Flag("no-color").Value)),
+				availability.DecodeOutput(asBool(cmd.Flag("decode").Value)),
+				availability.FormatOutput(cmd.Flag("format").Value.String()),
+				availability.OutputForPrinting(cmd.OutOrStdout()),
+			).
+			PrintDiff(availability.Compare(before, after))
+	},
+}
+
+func init() {
+	flags := diffCmd.Flags()
+	flags.BoolP("decode", "d", false, "decode URLs")
+	flags.StringP("format", "f", availability.TextFormat, "output format: text or json")
+	flags.Bool("no-color", false, "disable colorized output")
+}
+
+func loadSites(path string) ([]availability.Site, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { unsafe.Ignore(file.Close()) }()
+	return availability.LoadSites(file)
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.octolab.org/unsafe"
+)
+
+func TestDiff(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	cmd := diffCmd
+	cmd.SetOutput(buf)
+	defer cmd.SetOutput(nil)
+
+	dir, err := ioutil.TempDir("", "check")
+	assert.NoError(t, err)
+	defer func() { unsafe.Ignore(os.RemoveAll(dir)) }()
+	before, after := filepath.Join(dir, "old.json"), filepath.Join(dir, "new.json")
+	assert.NoError(t, ioutil.WriteFile(before, []byte(`[
+{"name":"test.dev","pages":[{"status_code":200,"location":"http://test.dev/","internal":true,"links":[
+	{"status_code":200,"location":"http://test.dev/about/","internal":true}
+]}],"problems":[]}
+]`), 0644))
+	assert.NoError(t, ioutil.WriteFile(after, []byte(`[
+{"name":"test.dev","pages":[{"status_code":200,"location":"http://test.dev/","internal":true,"links":[
+	{"status_code":404,"location":"http://test.dev/about/","internal":true}
+]}],"problems":[]}
+]`), 0644))
+
+	assert.NoError(t, cmd.RunE(cmd, []string{before, after}))
+	assert.Contains(t, buf.String(), "(changed) [200 -> 404] http://test.dev/about/")
+	assert.Error(t, cmd.RunE(cmd, []string{before, filepath.Join(dir, "unknown.json")}))
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -11,7 +12,7 @@ import (
 var RootCmd = &cobra.Command{Use: "check"}
 
 func init() {
-	RootCmd.AddCommand(completionCmd, urlsCmd)
+	RootCmd.AddCommand(completionCmd, diffCmd, urlsCmd)
 }
 
 func asBool(value fmt.Stringer) bool {
@@ -19,6 +20,21 @@ func asBool(value fmt.Stringer) bool {
 	return is
 }
 
+func asDuration(value fmt.Stringer) time.Duration {
+	duration, _ := time.ParseDuration(value.String())
+	return duration
+}
+
+func asFloat(value fmt.Stringer) float64 {
+	number, _ := strconv.ParseFloat(value.String(), 64)
+	return number
+}
+
+func asInt(value fmt.Stringer) int {
+	number, _ := strconv.Atoi(value.String())
+	return number
+}
+
 func client(cmd *cobra.Command) string {
 	var version *cobra.Command
 	if cmd.Parent() != nil {
@@ -1,10 +1,19 @@
 package cmd
 
 import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"regexp"
 	"time"
 
 	"github.com/briandowns/spinner"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.octolab.org/unsafe"
 
 	"github.com/kamilsk/check/http/availability"
 )
@@ -12,10 +21,99 @@ import (
 var urlsCmd = &cobra.Command{
 	Use:   "urls",
 	Short: "Check all internal URLs on availability",
-	Args:  cobra.MinimumNArgs(1),
+	Long: "Check all internal URLs on availability.\n\n" +
+		"Options are read from the " + configFile + " file in the working directory or one specified by --config.\n" +
+		"Flags override options of websites, which override the default ones.",
+	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		cnf, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if len(args) == 0 {
+			args = cnf.urls()
+		}
+		if len(args) == 0 {
+			return fmt.Errorf("requires at least one URL as an argument or a site in the config file")
+		}
+		flags, err := cnf.flags(cmd, nil)
+		if err != nil {
+			return err
+		}
+		categories, err := flags.GetStringSlice("fail-on")
+		if err != nil {
+			return err
+		}
+		policy, err := availability.NewFailPolicy(categories...)
+		if err != nil {
+			return err
+		}
+		kinds, err := flags.GetStringSlice("kind")
+		if err != nil {
+			return err
+		}
+		if err = availability.ValidateKinds(kinds); err != nil {
+			return err
+		}
+		only, err := flags.GetStringSlice("only")
+		if err != nil {
+			return err
+		}
+		hidden, err := flags.GetStringSlice("hide")
+		if err != nil {
+			return err
+		}
+		if err = availability.ValidateClasses(append(only, hidden...)); err != nil {
+			return err
+		}
+		ignored, err := patterns(flags, "ignore")
+		if err != nil {
+			return err
+		}
+		defaults, err := crawlerConfig(cmd, flags)
+		if err != nil {
+			return err
+		}
+		// the default limits of requests are global,
+		// sites with their own profiles are also limited by their own ones
+		defaults.Throttle = availability.NewThrottle(defaults)
+		options := []func(*availability.Report){
+			availability.CrawlerForSites(availability.CrawlerColly(defaults)),
+			availability.ConcurrentSites(asInt(flags.Lookup("concurrency").Value)),
+			availability.IgnoreURLs(ignored),
+		}
+		baselineFile := flags.Lookup("baseline").Value.String()
+		updateBaseline := asBool(flags.Lookup("update-baseline").Value)
+		if updateBaseline && baselineFile == "" {
+			return fmt.Errorf("--update-baseline requires a path to the baseline file specified by --baseline")
+		}
+		if baselineFile != "" {
+			baseline, err := loadBaseline(baselineFile, updateBaseline)
+			if err != nil {
+				return err
+			}
+			if baseline != nil {
+				options = append(options, availability.CompareWith(baseline))
+			}
+		}
+		for _, url := range args {
+			site, specified := cnf.profile(url)
+			if !specified {
+				continue
+			}
+			siteFlags, err := cnf.flags(cmd, site)
+			if err != nil {
+				return fmt.Errorf("site %q: %v", url, err)
+			}
+			config, err := crawlerConfig(cmd, siteFlags)
+			if err != nil {
+				return fmt.Errorf("site %q: %v", url, err)
+			}
+			config.Throttle = defaults.Throttle
+			options = append(options, availability.CrawlerForSite(url, availability.CrawlerColly(config)))
+		}
 		var spin = func() func() { return func() {} }
-		verbose := asBool(cmd.Flag("verbose").Value)
+		verbose := asBool(flags.Lookup("verbose").Value)
 		if !verbose {
 			spin = func() func() {
 				s := spinner.New(spinner.CharSets[34], 100*time.Millisecond)
@@ -24,36 +122,259 @@ var urlsCmd = &cobra.Command{
 				return s.Stop
 			}
 		}
+		output := cmd.OutOrStdout()
+		if path := flags.Lookup("output").Value.String(); path != "" {
+			file, err := os.Create(path)
+			if err != nil {
+				return err
+			}
+			defer func() { unsafe.Ignore(file.Close()) }()
+			output = file
+		}
 		stop := spin()
-		report := availability.NewReport(
-			availability.CrawlerForSites(availability.CrawlerColly(
-				availability.CrawlerConfig{
-					UserAgent: client(cmd),
-					Verbose:   verbose,
-					Output:    cmd.OutOrStderr(),
-				},
-			)),
-		).
+		report := availability.NewReport(options...).
 			For(args).
 			Fill()
 		stop()
-		return availability.
+		err = availability.
 			NewPrinter(
-				availability.ColorizeOutput(!asBool(cmd.Flag("no-color").Value)),
-				availability.DecodeOutput(asBool(cmd.Flag("decode").Value)),
-				availability.HideError(asBool(cmd.Flag("no-error").Value)),
-				availability.HideRedirect(asBool(cmd.Flag("no-redirect").Value)),
-				availability.OutputForPrinting(cmd.OutOrStdout()),
+				availability.ColorizeOutput(!asBool(flags.Lookup("no-color").Value)),
+				availability.DecodeOutput(asBool(flags.Lookup("decode").Value)),
+				availability.FilterKinds(kinds...),
+				availability.FormatOutput(flags.Lookup("format").Value.String()),
+				availability.GroupBy(flags.Lookup("group-by").Value.String()),
+				availability.HideClasses(hidden...),
+				availability.HideError(asBool(flags.Lookup("no-error").Value)),
+				availability.HideRedirect(asBool(flags.Lookup("no-redirect").Value)),
+				availability.OnlyClasses(only...),
+				availability.ShowAttempts(asBool(flags.Lookup("show-attempts").Value)),
+				availability.ShowExcluded(verbose),
+				availability.OutputForPrinting(output),
 			).
 			For(report).
 			Print()
+		if err != nil {
+			return err
+		}
+		if updateBaseline {
+			buf := bytes.NewBuffer(nil)
+			if err = report.WriteBaseline(buf); err != nil {
+				return err
+			}
+			if err = ioutil.WriteFile(baselineFile, buf.Bytes(), 0644); err != nil {
+				return err
+			}
+		}
+		if err = policy.Check(report); err != nil {
+			cmd.SilenceUsage = true
+		}
+		return err
 	},
 }
 
+// crawlerConfig returns a website crawler's configuration built from the flags.
+func crawlerConfig(cmd *cobra.Command, flags *pflag.FlagSet) (availability.CrawlerConfig, error) {
+	var config availability.CrawlerConfig
+	retryOn, err := flags.GetStringSlice("retry-on")
+	if err != nil {
+		return config, err
+	}
+	if err = availability.ValidateRetryOn(retryOn); err != nil {
+		return config, err
+	}
+	allowedHosts, err := flags.GetStringArray("allowed-host")
+	if err != nil {
+		return config, err
+	}
+	include, err := patterns(flags, "include")
+	if err != nil {
+		return config, err
+	}
+	exclude, err := patterns(flags, "exclude")
+	if err != nil {
+		return config, err
+	}
+	credentials, err := credentials(flags)
+	if err != nil {
+		return config, err
+	}
+	cookies, err := cookies(flags)
+	if err != nil {
+		return config, err
+	}
+	var proxy *url.URL
+	if raw := flags.Lookup("proxy").Value.String(); raw != "" {
+		if proxy, err = url.Parse(raw); err != nil {
+			return config, fmt.Errorf("invalid proxy URL %q: %v", raw, err)
+		}
+	}
+	rootCAs, err := certificates(flags)
+	if err != nil {
+		return config, err
+	}
+	overrides, err := flags.GetStringArray("resolve")
+	if err != nil {
+		return config, err
+	}
+	resolve, err := availability.ParseResolve(overrides)
+	if err != nil {
+		return config, err
+	}
+	switch external := flags.Lookup("external").Value.String(); external {
+	case availability.ExternalCheck, availability.ExternalSkip, availability.ExternalList:
+	default:
+		return config, fmt.Errorf("unsupported external mode %q", external)
+	}
+	value := func(name string) pflag.Value { return flags.Lookup(name).Value }
+	return availability.CrawlerConfig{
+		UserAgent: client(cmd),
+		Verbose:   asBool(value("verbose")),
+		Output:    cmd.OutOrStderr(),
+		MaxDepth:  asInt(value("max-depth")),
+		MaxPages:  asInt(value("max-pages")),
+		Timeout:   asDuration(value("timeout")),
+
+		AllowedHosts: allowedHosts,
+		SameSite:     asBool(value("same-site")),
+
+		External:            value("external").String(),
+		ExternalTimeout:     asDuration(value("external-timeout")),
+		ExternalConcurrency: asInt(value("external-concurrency")),
+
+		Concurrency: asInt(value("concurrency")),
+		PerHost:     asInt(value("per-host")),
+
+		Delay:             asDuration(value("delay")),
+		Jitter:            asDuration(value("jitter")),
+		RequestsPerSecond: asFloat(value("rps")),
+
+		Retries: asInt(value("retries")),
+		Backoff: asDuration(value("backoff")),
+		RetryOn: retryOn,
+
+		Fragments: asBool(value("check-fragments")),
+		Sitemap:   asBool(value("sitemap")),
+
+		RespectRobots:   asBool(value("respect-robots")),
+		FollowRedirects: asInt(value("follow-redirects")),
+
+		Credentials: credentials,
+		Cookies:     cookies,
+
+		ConnectTimeout:      asDuration(value("connect-timeout")),
+		TLSHandshakeTimeout: asDuration(value("tls-handshake-timeout")),
+		ResponseTimeout:     asDuration(value("response-timeout")),
+		Resolve:             resolve,
+		Proxy:               proxy,
+		RootCAs:             rootCAs,
+
+		Include: include,
+		Exclude: exclude,
+	}, nil
+}
+
+// loadBaseline reads the baseline file.
+// It returns nil if the file doesn't exist yet and is going to be created.
+func loadBaseline(path string, update bool) (*availability.Baseline, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if update && os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() { unsafe.Ignore(file.Close()) }()
+	return availability.LoadBaseline(file)
+}
+
 func init() {
-	urlsCmd.Flags().BoolP("decode", "d", false, "decode URLs")
-	urlsCmd.Flags().Bool("no-color", false, "disable colorized output")
-	urlsCmd.Flags().Bool("no-error", false, "do not show URL's error")
-	urlsCmd.Flags().Bool("no-redirect", false, "do not show URL's redirect")
-	urlsCmd.Flags().BoolP("verbose", "v", false, "turn on verbose mode")
+	urlsFlags(urlsCmd.Flags())
+}
+
+func urlsFlags(flags *pflag.FlagSet) {
+	flags.StringArray("allowed-host", nil,
+		"treat the host as a part of the website, e.g. www.example.com for example.com")
+	flags.StringArray("auth-host", nil,
+		"send headers and credentials to the host in addition to the host of the website")
+	flags.Duration("backoff", time.Second, "delay before the first retry, it doubles with each next one")
+	flags.String("baseline", "", "path to a JSON report of a previous check, only new issues are reported")
+	flags.String("basic-auth", "",
+		"user:password for requests to the website, "+basicAuthEnv+" is used by default")
+	flags.String("bearer-token", "",
+		"token for requests to the website, "+bearerTokenEnv+" is used by default")
+	flags.String("ca-bundle", "", "path to PEM encoded certificates of authorities trusted in addition to system ones")
+	flags.Bool("check-fragments", false, "verify that fragments of links to internal pages exist")
+	flags.IntP("concurrency", "c", 1, "limit count of concurrent requests")
+	flags.String(configFlag, "", "path to a config file, "+configFile+" in the working directory is used by default")
+	flags.Duration("connect-timeout", 0, "limit duration of establishing a connection, 0 means 30s")
+	flags.String("cookies", "", "path to a cookies file in the Netscape format, cookies are disabled by default")
+	flags.BoolP("decode", "d", false, "decode URLs")
+	flags.Duration("delay", 0, "delay between requests to the same host")
+	flags.StringArray("exclude", nil,
+		"do not request URLs matched the glob or the regular expression prefixed by re:, e.g. '*/logout*'")
+	flags.String("external", availability.ExternalCheck, "handle links to other hosts: check, skip or list")
+	flags.Int("external-concurrency", 1, "limit count of concurrent requests to other hosts")
+	flags.Duration("external-timeout", 10*time.Second, "limit duration of a request to other hosts")
+	flags.StringSlice("fail-on", nil, "fail if found: 3xx (redirect), 4xx, 5xx, error, timeout, anchor or problem")
+	flags.Int("follow-redirects", 0, "follow redirect chains up to the number of hops, 0 means disabled")
+	flags.StringP("format", "f", availability.TextFormat, "output format: text, json, junit or html")
+	flags.String("group-by", availability.PageGrouping, "group links in the text output by: page or link")
+	flags.StringArray("header", nil, "add the header to requests to the website, e.g. 'X-Api-Key: secret'")
+	flags.StringSlice("hide", nil,
+		"do not show links of status classes: 2xx, 3xx (redirect), 4xx, 5xx, error, timeout, anchor or skipped")
+	flags.StringArray("ignore", nil,
+		"do not show and fail on URLs matched the glob or the regular expression prefixed by re:, they are still requested")
+	flags.StringArray("include", nil,
+		"request only URLs matched the glob or the regular expression prefixed by re:")
+	flags.Duration("jitter", 0, "maximal random delay added to the delay between requests")
+	flags.StringSlice("kind", nil, "show only links of kinds: anchor, image, script, style, media or frame")
+	flags.Int("max-depth", 0, "limit depth of walked pages, 0 means unlimited")
+	flags.Int("max-pages", 0, "limit count of walked pages, 0 means unlimited")
+	flags.Bool("no-color", false, "disable colorized output")
+	flags.Bool("no-error", false, "do not show URL's error")
+	flags.Bool("no-redirect", false, "do not show URL's redirect")
+	flags.StringSlice("only", nil,
+		"show only links of status classes: 2xx, 3xx (redirect), 4xx, 5xx, error, timeout, anchor or skipped")
+	flags.StringP("output", "o", "", "write the report into the file instead of stdout")
+	flags.Int("per-host", 0, "limit count of concurrent requests to the same host, 0 means unlimited")
+	flags.String("proxy", "", "URL of an HTTP proxy, HTTP_PROXY and HTTPS_PROXY are used by default")
+	flags.StringArray("resolve", nil,
+		"connect to the address instead of the host and port, e.g. www.example.com:443:10.0.0.1")
+	flags.Bool("respect-robots", false, "skip URLs disallowed by robots.txt of their hosts for the user agent")
+	flags.Duration("response-timeout", 0, "limit duration of waiting for response headers, 0 means unlimited")
+	flags.Int("retries", 0, "limit retries of a failed request")
+	flags.StringSlice("retry-on", availability.DefaultRetryOn,
+		"retry on: timeout, connection or any network error, a status code or its class, e.g. 503 or 5xx")
+	flags.Float64("rps", 0, "limit requests per second to the same host, 0 means unlimited")
+	flags.Bool("same-site", false,
+		"treat hosts of the same registrable domain as a part of the website, e.g. docs.example.com")
+	flags.Bool("show-attempts", false, "show how many times a URL has been requested")
+	flags.Bool("sitemap", false, "crawl pages listed in sitemaps and compare them with found links")
+	flags.Duration("timeout", 0, "limit duration of a website crawling, 0 means unlimited")
+	flags.Duration("tls-handshake-timeout", 0, "limit duration of a TLS handshake, 0 means 10s")
+	flags.Bool("update-baseline", false, "write the current state into the baseline file")
+	flags.BoolP("verbose", "v", false, "turn on verbose mode")
+}
+
+// certificates returns certificates of authorities loaded from the file
+// specified by the flag or nil if it's not specified.
+func certificates(flags *pflag.FlagSet) (*x509.CertPool, error) {
+	path := flags.Lookup("ca-bundle").Value.String()
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { unsafe.Ignore(file.Close()) }()
+	return availability.LoadCertificates(file)
+}
+
+func patterns(flags *pflag.FlagSet, name string) ([]*regexp.Regexp, error) {
+	values, err := flags.GetStringArray(name)
+	if err != nil {
+		return nil, err
+	}
+	return availability.CompilePatterns(values)
 }
@@ -3,10 +3,18 @@ package cmd
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"go.octolab.org/unsafe"
+
+	"github.com/kamilsk/check/errors"
+	"github.com/kamilsk/check/http/availability"
 )
 
 func TestURLs(t *testing.T) {
@@ -28,4 +36,59 @@ func TestURLs(t *testing.T) {
 		assert.NoError(t, cmd.RunE(cmd, []string{site.URL + "/"}))
 		unsafe.Ignore(verbose.Value.Set(verbose.DefValue))
 	}
+	{
+		buf.Reset()
+		failOn := cmd.Flag("fail-on")
+		redirect := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == "/old" {
+				http.Redirect(rw, req, "/", http.StatusMovedPermanently)
+				return
+			}
+			unsafe.Ignore(tpl.Execute(rw, struct {
+				Href string
+				Text string
+			}{"/old", "Old"}))
+		}))
+		defer redirect.Close()
+		unsafe.Ignore(failOn.Value.Set(availability.RedirectCategory))
+		assert.NoError(t, cmd.RunE(cmd, []string{site.URL + "/"}))
+		err := cmd.RunE(cmd, []string{redirect.URL + "/"})
+		var violation *availability.PolicyViolation
+		assert.True(t, errors.As(err, &violation))
+		assert.Equal(t, 3, violation.ExitCode())
+		unsafe.Ignore(failOn.Value.(interface{ Replace([]string) error }).Replace(nil))
+	}
+	{
+		buf.Reset()
+		update := cmd.Flag("update-baseline")
+		unsafe.Ignore(update.Value.Set("true"))
+		assert.Error(t, cmd.RunE(cmd, []string{site.URL + "/"}))
+
+		dir, err := ioutil.TempDir("", "check")
+		assert.NoError(t, err)
+		defer func() { unsafe.Ignore(os.RemoveAll(dir)) }()
+		baseline := cmd.Flag("baseline")
+		unsafe.Ignore(baseline.Value.Set(filepath.Join(dir, "baseline.json")))
+		assert.NoError(t, cmd.RunE(cmd, []string{site.URL + "/"}))
+		unsafe.Ignore(update.Value.Set(update.DefValue))
+		assert.NoError(t, cmd.RunE(cmd, []string{site.URL + "/"}))
+		assert.FileExists(t, filepath.Join(dir, "baseline.json"))
+		unsafe.Ignore(baseline.Value.Set(baseline.DefValue))
+	}
+	{
+		buf.Reset()
+		dir, err := ioutil.TempDir("", "check")
+		assert.NoError(t, err)
+		defer func() { unsafe.Ignore(os.RemoveAll(dir)) }()
+		format, output := cmd.Flag("format"), cmd.Flag("output")
+		unsafe.Ignore(format.Value.Set("html"))
+		unsafe.Ignore(output.Value.Set(filepath.Join(dir, "report.html")))
+		assert.NoError(t, cmd.RunE(cmd, []string{site.URL + "/"}))
+		unsafe.Ignore(format.Value.Set(format.DefValue))
+		unsafe.Ignore(output.Value.Set(output.DefValue))
+		report, err := ioutil.ReadFile(filepath.Join(dir, "report.html"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(report), "<!DOCTYPE html>")
+		assert.NotContains(t, buf.String(), "<!DOCTYPE html>")
+	}
 }
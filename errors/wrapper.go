@@ -7,11 +7,21 @@ import (
 	"github.com/pkg/errors"
 )
 
+// As is a proxy for `github.com/pkg/errors.As`.
+func As(err error, target interface{}) bool {
+	return errors.As(err, target)
+}
+
 // Errorf is a proxy for `github.com/pkg/errors.Errorf`.
 func Errorf(format string, args ...interface{}) error {
 	return errors.Errorf(format, args...)
 }
 
+// Is is a proxy for `github.com/pkg/errors.Is`.
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
 // Recover recovers execution flow and sets error to the passed error pointer.
 func Recover(err *error) {
 	if r := recover(); r != nil {
@@ -8,7 +8,11 @@ require (
 	github.com/gocolly/colly/v2 v2.1.0
 	github.com/pkg/errors v0.9.1
 	github.com/spf13/cobra v1.1.1
+	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.6.1
+	github.com/temoto/robotstxt v1.1.1
 	go.octolab.org v0.2.0
 	go.octolab.org/toolkit/cli v0.2.0
+	golang.org/x/net v0.0.0-20200602114024-627f9648deb9
+	gopkg.in/yaml.v2 v2.3.0
 )
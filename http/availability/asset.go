@@ -0,0 +1,74 @@
+package availability
+
+import (
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+
+	"github.com/kamilsk/check/errors"
+)
+
+// Supported kinds of links.
+const (
+	AnchorKind = "anchor"
+	ImageKind  = "image"
+	ScriptKind = "script"
+	StyleKind  = "style"
+	MediaKind  = "media"
+	FrameKind  = "frame"
+)
+
+// ValidateKinds checks that all passed kinds of links are supported.
+func ValidateKinds(kinds []string) error {
+	for _, kind := range kinds {
+		switch kind {
+		case AnchorKind, ImageKind, ScriptKind, StyleKind, MediaKind, FrameKind:
+			continue
+		}
+		return errors.Errorf("unsupported link kind %q", kind)
+	}
+	return nil
+}
+
+// asset describes how references of the same kind are found on a page.
+type asset struct {
+	kind     string
+	selector string
+	attr     string
+}
+
+// assets are page resources checked by HEAD requests, they are never walked.
+var assets = []asset{
+	{ImageKind, "img[src]", "src"},
+	{ImageKind, "img[srcset]", "srcset"},
+	{ImageKind, "picture source[srcset]", "srcset"},
+	{ScriptKind, "script[src]", "src"},
+	{StyleKind, "link[href][rel~=stylesheet]", "href"},
+	{MediaKind, "audio[src], video[src], track[src]", "src"},
+	{MediaKind, "video[poster]", "poster"},
+	{MediaKind, "audio source[src], video source[src]", "src"},
+	{MediaKind, "audio source[srcset], video source[srcset]", "srcset"},
+	{FrameKind, "iframe[src], frame[src]", "src"},
+}
+
+// references returns all references of the asset found in the element.
+func (a asset) references(el *colly.HTMLElement) []string {
+	value := el.Attr(a.attr)
+	if a.attr != "srcset" {
+		return []string{value}
+	}
+	return srcset(value)
+}
+
+// srcset parses a value of the srcset attribute, which contains
+// comma-separated image candidates, e.g. "small.png 1x, large.png 2x".
+func srcset(value string) []string {
+	candidates := strings.Split(value, ",")
+	refs := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if fields := strings.Fields(candidate); len(fields) > 0 {
+			refs = append(refs, fields[0])
+		}
+	}
+	return refs
+}
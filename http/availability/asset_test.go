@@ -0,0 +1,80 @@
+package availability_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.octolab.org/unsafe"
+
+	"github.com/kamilsk/check/http/availability"
+)
+
+func TestCrawlerColly_assets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/":
+			unsafe.DoSilent(io.WriteString(rw, `<!doctype html>
+<html lang="en">
+<head>
+    <link rel="stylesheet" href="/style.css">
+    <link rel="icon" href="/favicon.ico">
+    <script src="/script.js"></script>
+</head>
+<body>
+<a href="/page">page</a>
+<img src="/image.png" srcset="/image.png 1x, /image@2x.png 2x">
+<img src="data:image/png;base64,iVBORw0KGgo=">
+<picture><source srcset="/picture.webp"></picture>
+<video src="/video.mp4" poster="/poster.png"><source src="/video.webm"></video>
+<iframe src="/frame"></iframe>
+</body>
+</html>`))
+		case "/page", "/style.css", "/script.js", "/image.png", "/picture.webp", "/video.mp4", "/frame":
+			rw.WriteHeader(http.StatusOK)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	kinds, statuses := make(map[string]string), make(map[string]int)
+	crawler := availability.CrawlerColly(availability.CrawlerConfig{})
+	events, err := collect(crawler, server.URL+"/")
+	assert.NoError(t, err)
+	for _, event := range events {
+		switch e := event.(type) {
+		case availability.ErrorEvent:
+			statuses[e.Location] = e.StatusCode
+		case availability.ResponseEvent:
+			statuses[e.Location] = e.StatusCode
+		case availability.WalkEvent:
+			kinds[e.Href] = e.Kind
+		}
+	}
+
+	assert.Equal(t, map[string]string{
+		server.URL + "/page":         availability.AnchorKind,
+		server.URL + "/style.css":    availability.StyleKind,
+		server.URL + "/script.js":    availability.ScriptKind,
+		server.URL + "/image.png":    availability.ImageKind,
+		server.URL + "/image@2x.png": availability.ImageKind,
+		server.URL + "/picture.webp": availability.ImageKind,
+		server.URL + "/video.mp4":    availability.MediaKind,
+		server.URL + "/poster.png":   availability.MediaKind,
+		server.URL + "/video.webm":   availability.MediaKind,
+		server.URL + "/frame":        availability.FrameKind,
+	}, kinds)
+	assert.Equal(t, http.StatusOK, statuses[server.URL+"/image.png"])
+	assert.Equal(t, http.StatusNotFound, statuses[server.URL+"/image@2x.png"])
+	assert.Equal(t, http.StatusNotFound, statuses[server.URL+"/poster.png"])
+	assert.Equal(t, http.StatusNotFound, statuses[server.URL+"/video.webm"])
+	assert.Len(t, statuses, len(kinds)+1)
+}
+
+func TestValidateKinds(t *testing.T) {
+	assert.NoError(t, availability.ValidateKinds([]string{availability.AnchorKind, availability.ImageKind}))
+	assert.Error(t, availability.ValidateKinds([]string{"font"}))
+}
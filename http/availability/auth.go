@@ -0,0 +1,130 @@
+package availability
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+
+	"github.com/kamilsk/check/errors"
+)
+
+const (
+	authorizationHeader = "Authorization"
+	httpOnlyPrefix      = "#HttpOnly_"
+)
+
+// Credential contains headers and authentication of requests to a host.
+// A bearer token takes precedence over basic authentication.
+type Credential struct {
+	// Host limits the credential by requests to the host,
+	// the host of a website's entry point is used if it's empty.
+	Host     string
+	Header   http.Header
+	Username string
+	Password string
+	Token    string
+}
+
+// apply adds headers and authentication of the credential to the request's header.
+func (credential Credential) apply(header http.Header) {
+	for name, values := range credential.Header {
+		header.Del(name)
+		for _, value := range values {
+			header.Add(name, value)
+		}
+	}
+	switch {
+	case credential.Token != "":
+		header.Set(authorizationHeader, "Bearer "+credential.Token)
+	case credential.Username != "" || credential.Password != "":
+		auth := base64.StdEncoding.EncodeToString([]byte(credential.Username + ":" + credential.Password))
+		header.Set(authorizationHeader, "Basic "+auth)
+	}
+}
+
+// scope returns credentials with the host of the entry point instead of empty ones.
+func scope(credentials []Credential, base *url.URL) []Credential {
+	scoped := make([]Credential, 0, len(credentials))
+	for _, credential := range credentials {
+		if credential.Host == "" {
+			credential.Host = base.Host
+		}
+		scoped = append(scoped, credential)
+	}
+	return scoped
+}
+
+// authorize applies credentials scoped by the host of the URL to the request's header,
+// so they are never sent to other hosts.
+func authorize(credentials []Credential, u *url.URL, header http.Header) {
+	for _, credential := range credentials {
+		if strings.EqualFold(credential.Host, u.Host) || strings.EqualFold(credential.Host, u.Hostname()) {
+			credential.apply(header)
+		}
+	}
+}
+
+// UseCookies sets the cookie jar for `github.com/gocolly/colly.Collector`.
+func UseCookies(jar http.CookieJar) func(*colly.Collector) {
+	return func(c *colly.Collector) {
+		c.SetCookieJar(jar)
+	}
+}
+
+// LoadCookies reads cookies from a file in the Netscape format used by curl and wget.
+// Each line contains tab-separated domain, subdomains flag, path, secure flag,
+// expiration as a Unix time, name and value of a cookie.
+func LoadCookies(r io.Reader) (http.CookieJar, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		httpOnly := strings.HasPrefix(line, httpOnlyPrefix)
+		line = strings.TrimPrefix(line, httpOnlyPrefix)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, errors.Errorf("invalid cookie at line %d: expected 7 fields, obtained %d", number, len(fields))
+		}
+		expiration, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid expiration of cookie at line %d", number)
+		}
+		domain, secure := strings.TrimPrefix(fields[0], "."), strings.EqualFold(fields[3], "TRUE")
+		cookie := &http.Cookie{
+			Name:     fields[5],
+			Value:    fields[6],
+			Path:     fields[2],
+			Secure:   secure,
+			HttpOnly: httpOnly,
+		}
+		if strings.EqualFold(fields[1], "TRUE") {
+			cookie.Domain = domain
+		}
+		if expiration > 0 {
+			cookie.Expires = time.Unix(expiration, 0)
+		}
+		scheme := "http"
+		if secure {
+			scheme = "https"
+		}
+		jar.SetCookies(&url.URL{Scheme: scheme, Host: domain, Path: cookie.Path}, []*http.Cookie{cookie})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "read cookies")
+	}
+	return jar, nil
+}
@@ -0,0 +1,123 @@
+package availability_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.octolab.org/unsafe"
+
+	"github.com/kamilsk/check/http/availability"
+)
+
+func TestCrawlerColly_credentials(t *testing.T) {
+	var mu sync.Mutex
+	leaked := make([]string, 0, 2)
+	external := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		leaked = append(leaked, req.Header.Get("Authorization"), req.Header.Get("X-Api-Key"))
+		mu.Unlock()
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer external.Close()
+	site := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		user, password, ok := req.BasicAuth()
+		if !ok || user != "user" || password != "secret" || req.Header.Get("X-Api-Key") != "key" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if cookie, err := req.Cookie("session"); err != nil || cookie.Value != "token" {
+			rw.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if req.URL.Path != "/" {
+			rw.WriteHeader(http.StatusOK)
+			return
+		}
+		unsafe.Ignore(tpl.Execute(rw, []struct {
+			Href string
+			Text string
+		}{
+			{Href: "/protected", Text: "protected"},
+			{Href: external.URL + "/", Text: "external"},
+		}))
+	}))
+	defer site.Close()
+	u, err := url.Parse(site.URL)
+	assert.NoError(t, err)
+	jar, err := availability.LoadCookies(strings.NewReader(
+		"# Netscape HTTP Cookie File\n" + u.Hostname() + "\tFALSE\t/\tFALSE\t0\tsession\ttoken\n"))
+	assert.NoError(t, err)
+
+	statuses := make(map[string]int)
+	credential := availability.Credential{
+		Header:   http.Header{"X-Api-Key": {"key"}},
+		Username: "user",
+		Password: "secret",
+	}
+	crawler := availability.CrawlerColly(availability.CrawlerConfig{
+		Credentials: []availability.Credential{credential},
+		Cookies:     jar,
+	})
+	events, err := collect(crawler, site.URL+"/")
+	assert.NoError(t, err)
+	for _, event := range events {
+		switch e := event.(type) {
+		case availability.ResponseEvent:
+			statuses[e.Location] = e.StatusCode
+		case availability.ErrorEvent:
+			statuses[e.Location] = e.StatusCode
+		}
+	}
+	assert.Equal(t, map[string]int{
+		site.URL + "/":          http.StatusOK,
+		site.URL + "/protected": http.StatusOK,
+		external.URL + "/":      http.StatusOK,
+	}, statuses)
+	assert.Equal(t, []string{"", ""}, leaked)
+
+	t.Run("scoped by host", func(t *testing.T) {
+		leaked = leaked[:0]
+		crawler := availability.CrawlerColly(availability.CrawlerConfig{
+			Credentials: []availability.Credential{
+				credential,
+				{Host: strings.TrimPrefix(external.URL, "http://"), Token: "token"},
+			},
+			Cookies: jar,
+		})
+		assert.NoError(t, crawler.Visit(site.URL+"/", availability.NewReadableEventBus(8)))
+		assert.Equal(t, []string{"Bearer token", ""}, leaked)
+	})
+}
+
+func TestLoadCookies(t *testing.T) {
+	jar, err := availability.LoadCookies(strings.NewReader(`# Netscape HTTP Cookie File
+.example.com	TRUE	/	TRUE	0	secure	1
+#HttpOnly_example.com	FALSE	/admin	FALSE	0	admin	2
+
+example.com	FALSE	/	FALSE	1	expired	3
+`))
+	assert.NoError(t, err)
+
+	names := func(rawURL string) []string {
+		u, err := url.Parse(rawURL)
+		assert.NoError(t, err)
+		names := make([]string, 0, 2)
+		for _, cookie := range jar.Cookies(u) {
+			names = append(names, cookie.Name)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"secure"}, names("https://www.example.com/"))
+	assert.Empty(t, names("http://www.example.com/"))
+	assert.ElementsMatch(t, []string{"secure", "admin"}, names("https://example.com/admin/"))
+
+	_, err = availability.LoadCookies(strings.NewReader("example.com\tFALSE\t/\n"))
+	assert.Error(t, err)
+	_, err = availability.LoadCookies(strings.NewReader("example.com\tFALSE\t/\tFALSE\tnever\tname\tvalue\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package availability
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/kamilsk/check/errors"
+)
+
+// LoadBaseline reads a report in the JSON format and returns it as a baseline.
+// Only links with issues are taken into account.
+func LoadBaseline(r io.Reader) (*Baseline, error) {
+	sites, err := LoadSites(r)
+	if err != nil {
+		return nil, errors.Wrapf(err, "load baseline")
+	}
+	baseline := &Baseline{sites: make(map[string]map[string]Link, len(sites))}
+	for _, site := range sites {
+		known := make(map[string]Link)
+		for _, ref := range site.References() {
+			if ref.Category() != "" {
+				known[ref.Location] = ref.Link
+			}
+		}
+		baseline.sites[site.Name] = known
+	}
+	return baseline, nil
+}
+
+// CompareWith sets the baseline to a report builder.
+// Issues found in the baseline are considered as known,
+// and links broken in it but found without issues by the current check as fixed.
+func CompareWith(baseline *Baseline) func(*Report) {
+	return func(r *Report) {
+		r.baseline = baseline
+	}
+}
+
+// WriteBaseline writes the filled report in the JSON format,
+// so it can be loaded by LoadBaseline for the next checks.
+func (r *Report) WriteBaseline(w io.Writer) error {
+	sites := make([]jsonSite, 0, len(r.sites))
+	for _, site := range r.sites {
+		sites = append(sites, encodeSite(site.copy()))
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sites)
+}
+
+// Baseline contains links with issues found by a previous check, grouped by websites.
+type Baseline struct {
+	sites map[string]map[string]Link
+}
+
+// compare marks links of the website with the same issues as in the baseline as known
+// and collects links broken in the baseline which are found without issues.
+func (b *Baseline) compare(site *Site) {
+	if b == nil || site.Error != nil {
+		return
+	}
+	known, present := b.sites[site.Name]
+	if !present {
+		return
+	}
+	current := make(map[string]string)
+	mark := func(link *Link) {
+		category := link.Category()
+		current[link.Location] = category
+		if category != "" && known[link.Location].Category() == category {
+			link.Known = true
+		}
+	}
+	for _, page := range site.Pages {
+		if page.Link != nil {
+			mark(page.Link)
+		}
+		for i := range page.Links {
+			mark(&page.Links[i])
+		}
+	}
+	site.Fixed = make([]Link, 0, 4)
+	for location, link := range known {
+		// links missing from the current check are not fixed,
+		// they could be removed or not crawled because of limits and filters
+		if category, seen := current[location]; seen && category == "" {
+			site.Fixed = append(site.Fixed, link)
+		}
+	}
+	sort.Slice(site.Fixed, func(i, j int) bool { return site.Fixed[i].Location < site.Fixed[j].Location })
+}
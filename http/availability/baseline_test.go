@@ -0,0 +1,82 @@
+package availability_test
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/kamilsk/check/errors"
+	"github.com/kamilsk/check/http/availability"
+)
+
+func TestBaseline(t *testing.T) {
+	report := func(options ...func(*availability.Report)) *availability.Report {
+		crawler := &CrawlerMock{shift: func(to availability.EventBus) {
+			to <- availability.ResponseEvent{StatusCode: http.StatusOK, Location: "http://test.dev/"}
+			to <- availability.WalkEvent{Page: "http://test.dev/", Href: "http://redirect.dev/"}
+			to <- availability.WalkEvent{Page: "http://test.dev/", Href: "http://noaccess.dev/"}
+			to <- availability.WalkEvent{Page: "http://test.dev/", Href: "http://unavailable.dev/"}
+			to <- availability.WalkEvent{Page: "http://test.dev/", Href: "http://unreachable.dev/"}
+			to <- availability.WalkEvent{Page: "http://test.dev/", Href: "http://repaired.dev/"}
+			to <- availability.ResponseEvent{StatusCode: http.StatusOK, Location: "http://repaired.dev/"}
+			to <- availability.ErrorEvent{StatusCode: http.StatusFound,
+				Location: "http://redirect.dev/", Redirect: "https://redirect.dev/"}
+			to <- availability.ErrorEvent{StatusCode: http.StatusForbidden, Location: "http://noaccess.dev/"}
+			to <- availability.ErrorEvent{StatusCode: http.StatusServiceUnavailable, Location: "http://unavailable.dev/"}
+			to <- availability.ErrorEvent{Location: "http://unreachable.dev/", Error: errors.Simple("no such host")}
+			close(to)
+		}}
+		crawler.On("Visit", "http://test.dev/", mock.Anything).Return(nil)
+		options = append(options, availability.CrawlerForSites(crawler))
+		return availability.NewReport(options...).For([]string{"http://test.dev/"}).Fill()
+	}
+	policy, err := availability.NewFailPolicy(
+		availability.RedirectCategory,
+		availability.ClientErrorCategory,
+		availability.ServerErrorCategory,
+		availability.ErrorCategory,
+	)
+	assert.NoError(t, err)
+
+	baseline, err := availability.LoadBaseline(strings.NewReader(`[
+{"name":"test.dev","pages":[{"status_code":200,"location":"http://test.dev/","internal":true,"links":[
+	{"status_code":403,"location":"http://noaccess.dev/","internal":false},
+	{"status_code":404,"location":"http://removed.dev/","internal":false},
+	{"status_code":404,"location":"http://repaired.dev/","internal":false},
+	{"status_code":500,"location":"http://unavailable.dev/","internal":false},
+	{"status_code":200,"location":"http://unreachable.dev/","internal":false}
+]}],"problems":[]}
+]`))
+	assert.NoError(t, err)
+	compared := report(availability.CompareWith(baseline))
+	err = policy.Check(compared)
+	assert.Error(t, err)
+	assert.Equal(t, "found issues: 1 error, 1 redirect", err.Error())
+
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(t, availability.NewPrinter(availability.OutputForPrinting(buf)).For(compared).Print())
+	assert.Equal(t, `[200] http://test.dev/
+    ├───[0] http://unreachable.dev/ -> (no such host)
+    ├───[200] http://repaired.dev/
+    └───[302] http://redirect.dev/ -> https://redirect.dev/
+fixed links since the baseline of the site "test.dev"
+- [404] http://repaired.dev/
+`, buf.String())
+
+	t.Run("update", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		assert.NoError(t, report().WriteBaseline(buf))
+		baseline, err := availability.LoadBaseline(buf)
+		assert.NoError(t, err)
+		assert.NoError(t, policy.Check(report(availability.CompareWith(baseline))))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := availability.LoadBaseline(strings.NewReader(`{"name":"test.dev"}`))
+		assert.Error(t, err)
+	})
+}
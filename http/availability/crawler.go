@@ -1,11 +1,16 @@
 package availability
 
 import (
+	"context"
+	"crypto/x509"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gocolly/colly/v2"
 	"github.com/gocolly/colly/v2/debug"
@@ -21,6 +26,22 @@ const (
 
 var clickOptions = []string{"anonym", "nolog"}
 
+// Supported modes of handling links to other hosts.
+const (
+	// ExternalCheck validates links by HEAD requests
+	// with fallback to GET if the method is not allowed.
+	ExternalCheck = "check"
+	// ExternalSkip excludes links from the report.
+	ExternalSkip = "skip"
+	// ExternalList includes links into the report without validation.
+	ExternalList = "list"
+
+	// ExternalSkipReason is a reason of not validated links to other hosts.
+	ExternalSkipReason = "external"
+	// TimeoutSkipReason is a reason of links not requested before the crawl timeout.
+	TimeoutSkipReason = "timeout"
+)
+
 // Crawler defines general behavior of website crawlers.
 type Crawler interface {
 	// Visit starts to crawl a website starting with the passed URL.
@@ -34,6 +55,99 @@ type CrawlerConfig struct {
 	UserAgent string
 	Verbose   bool
 	Output    io.Writer
+
+	// MaxDepth limits how deep pages are walked, the entry page has depth 1.
+	MaxDepth int
+	// MaxPages limits how many pages are walked.
+	MaxPages int
+	// Timeout limits the overall duration of a website crawling.
+	Timeout time.Duration
+
+	// External defines how links to other hosts are handled,
+	// ExternalCheck is used if it's empty.
+	External string
+	// ExternalTimeout limits a duration of a request to other hosts.
+	ExternalTimeout time.Duration
+	// ExternalConcurrency limits how many requests to other hosts
+	// are in flight at the same time.
+	ExternalConcurrency int
+
+	// Concurrency limits how many requests are in flight at the same time,
+	// the limit is shared between all crawled websites.
+	// Requests are sent asynchronously if it's greater than 1.
+	Concurrency int
+	// PerHost limits how many concurrent requests are sent to the same host.
+	PerHost int
+	// Throttle limits requests of all crawlers using it in addition to
+	// the Concurrency and the PerHost, e.g. of websites with their own configs.
+	Throttle *Throttle
+
+	// Delay is a minimal delay between requests to the same host.
+	Delay time.Duration
+	// Jitter is a maximal random delay added to the Delay.
+	Jitter time.Duration
+	// RequestsPerSecond limits a rate of requests to the same host.
+	RequestsPerSecond float64
+
+	// Retries limits how many times a failed request is sent again.
+	Retries int
+	// Backoff is a delay before the first retry, it doubles with each next one.
+	Backoff time.Duration
+	// RetryOn contains conditions of retries: kinds of network errors,
+	// status codes or their classes. DefaultRetryOn is used if it's empty.
+	RetryOn []string
+
+	// Fragments enables verification of fragments of links to internal pages.
+	Fragments bool
+	// Sitemap enables crawling of pages listed in sitemaps of a website.
+	Sitemap bool
+	// RespectRobots enables skipping of links disallowed by robots.txt
+	// for the UserAgent.
+	RespectRobots bool
+
+	// FollowRedirects enables tracing of redirect chains
+	// and limits how many hops are followed.
+	FollowRedirects int
+
+	// Credentials contains headers and authentication of requests
+	// scoped by hosts, so they are never sent to other ones.
+	Credentials []Credential
+	// Cookies contains cookies sent with requests, cookies are disabled if it's nil.
+	Cookies http.CookieJar
+
+	// ConnectTimeout limits a duration of establishing a connection.
+	ConnectTimeout time.Duration
+	// TLSHandshakeTimeout limits a duration of a TLS handshake.
+	TLSHandshakeTimeout time.Duration
+	// ResponseTimeout limits a duration of waiting for response headers
+	// after a request is sent.
+	ResponseTimeout time.Duration
+	// Resolve contains addresses dialed instead of "host:port" keys,
+	// links are still reported with their hosts. See ParseResolve.
+	Resolve map[string]string
+	// Proxy is a URL of an HTTP proxy, otherwise one is taken from the environment.
+	Proxy *url.URL
+	// RootCAs contains trusted certificates of authorities,
+	// the system ones are used if it's nil.
+	RootCAs *x509.CertPool
+
+	// transport is shared by all requests of the crawler.
+	transport http.RoundTripper
+	// limiter is shared by all collectors of a website crawling.
+	limiter *limiter
+
+	// AllowedHosts contains hosts which belong to the website in addition to
+	// the host of its entry point, e.g. www and non-www ones or mirrors.
+	// Links to them are walked and reported as internal.
+	AllowedHosts []string
+	// SameSite treats hosts of the same registrable domain as the entry point
+	// as ones which belong to the website, e.g. docs.example.com for example.com.
+	SameSite bool
+
+	// Include limits requested URLs by ones matched any of filters.
+	Include []*regexp.Regexp
+	// Exclude prevents requests to URLs matched any of filters.
+	Exclude []*regexp.Regexp
 }
 
 // CrawlerFunc adds possibility to use functions as a website crawler.
@@ -44,32 +158,161 @@ func (fn CrawlerFunc) Visit(url string, bus EventBus) error { return fn(url, bus
 
 // CrawlerColly returns configured website crawler.
 func CrawlerColly(config CrawlerConfig) Crawler {
+	throttles := []*Throttle{NewThrottle(config)}
+	if config.Throttle != nil {
+		throttles = append(throttles, config.Throttle)
+	}
+	// external checks always hold a slot of the semaphore, because the synchronous
+	// external collector is called from concurrent handlers of pages in the asynchronous mode
+	externalThrottle := &Throttle{semaphore: make(chan struct{}, 1)}
+	if config.ExternalConcurrency > 1 {
+		externalThrottle.semaphore = make(chan struct{}, config.ExternalConcurrency)
+	}
+	transport := newTransport(config)
 	return CrawlerFunc(func(entry string, bus EventBus) error {
 		defer close(bus)
 		base, err := url.Parse(entry)
 		if err != nil {
 			return errors.WithMessage(err, fmt.Sprintf("parse entry point URL %q", entry))
 		}
-		options := make([]colly.CollectorOption, 0, 9)
-		if config.UserAgent != "" {
-			options = append(options, colly.UserAgent(config.UserAgent))
+		config := config
+		config.Credentials = scope(config.Credentials, base)
+		config.limiter = newLimiter(config, bus)
+		config.transport = transport
+		if !config.limiter.deadline.IsZero() {
+			ctx, cancel := context.WithDeadline(context.Background(), config.limiter.deadline)
+			defer cancel()
+			config.transport = withContext(transport, ctx)
+		}
+		robots := newRobotsChecker(config, bus)
+		if !robots.allowed(base) {
+			return errors.Errorf("entry point URL %q is disallowed by robots.txt", entry)
 		}
-		if config.Verbose {
-			options = append(options, colly.Debugger(&debug.LogDebugger{Output: config.Output}))
+		var external *colly.Collector
+		if config.External == "" || config.External == ExternalCheck {
+			options := append(collectorOptions(config, externalThrottle),
+				RequestTimeout(config.ExternalTimeout),
+				AbortExpired(bus, config),
+				OnRequest(config.Credentials...),
+				OnError(bus, config),
+				OnResponse(bus),
+			)
+			external = colly.NewCollector(options...)
 		}
-		options = append(options,
-			colly.IgnoreRobotsTxt(),
-			NoCookie(),
-			NoRedirect(),
-			OnRequest(),
-			OnError(bus),
+		options := append(collectorOptions(config, throttles...),
+			AbortExpired(bus, config),
+			OnRequest(config.Credentials...),
+			OnError(bus, config),
 			OnResponse(bus),
-			OnHTML(base, bus),
+			OnHTML(base, bus, config, external, robots),
 		)
-		return colly.NewCollector(options...).Visit(entry)
+		c := colly.NewCollector(options...)
+		err = c.Visit(entry)
+		if config.Sitemap {
+			seed(c, base, config, bus, robots)
+		}
+		c.Wait()
+		if external != nil {
+			external.Wait()
+		}
+		return err
 	})
 }
 
+// seed visits pages listed in sitemaps of a website in addition to the entry point.
+// Pages disallowed by robots.txt are skipped.
+func seed(c *colly.Collector, base *url.URL, config CrawlerConfig, bus EventBus, robots *robotsChecker) {
+	reader := newSitemapReader(config)
+	pages, found := reader.read(base)
+	site := newSiteMatcher(base, config)
+	for _, problem := range reader.problems {
+		bus <- problem
+	}
+	if !found {
+		return
+	}
+	bus <- SitemapEvent{Pages: pages}
+	for _, page := range pages {
+		u, err := url.Parse(page)
+		if err != nil || !site.contains(u) {
+			continue
+		}
+		if isExcluded(page, config) {
+			bus <- SkipEvent{Location: page, Reason: ExcludedSkipReason}
+			continue
+		}
+		if !robots.allowed(u) {
+			bus <- SkipEvent{Location: page, Reason: RobotsSkipReason}
+			continue
+		}
+		unsafe.Ignore(c.Visit(page))
+	}
+}
+
+func collectorOptions(config CrawlerConfig, throttles ...*Throttle) []colly.CollectorOption {
+	options := make([]colly.CollectorOption, 0, 16)
+	if config.UserAgent != "" {
+		options = append(options, colly.UserAgent(config.UserAgent))
+	}
+	if config.Verbose {
+		options = append(options, colly.Debugger(&debug.LogDebugger{Output: config.Output}))
+	}
+	transport := config.transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	options = append(options, limitConcurrency(transport, throttles...))
+	if cap(throttles[0].semaphore) > 1 {
+		options = append(options, colly.Async(true))
+	}
+	if rule, limited := limitRule(config); limited {
+		options = append(options, LimitRequests(rule))
+	}
+	if len(config.Include) > 0 || len(config.Exclude) > 0 {
+		options = append(options, FilterURLs(config.Include, config.Exclude))
+	}
+	if config.Cookies != nil {
+		options = append(options, UseCookies(config.Cookies))
+	} else {
+		options = append(options, NoCookie())
+	}
+	return append(options,
+		colly.IgnoreRobotsTxt(),
+		NoRedirect(),
+	)
+}
+
+// LimitRequests sets the limit rule for `github.com/gocolly/colly.Collector`.
+func LimitRequests(rule colly.LimitRule) func(*colly.Collector) {
+	return func(c *colly.Collector) {
+		unsafe.Ignore(c.Limit(&rule))
+	}
+}
+
+// limitRule converts delays between requests to the same host into a rule.
+// The requests per second limit is achieved by a delay between requests
+// of each parallel worker. Concurrent requests to the same host
+// are limited by throttles.
+func limitRule(config CrawlerConfig) (colly.LimitRule, bool) {
+	rule := colly.LimitRule{
+		DomainGlob:  "*",
+		Parallelism: config.PerHost,
+		Delay:       config.Delay,
+		RandomDelay: config.Jitter,
+	}
+	if config.RequestsPerSecond > 0 {
+		parallelism := 1
+		if config.PerHost > 1 {
+			parallelism = config.PerHost
+		}
+		delay := time.Duration(float64(parallelism) * float64(time.Second) / config.RequestsPerSecond)
+		if delay > rule.Delay {
+			rule.Delay = delay
+		}
+	}
+	return rule, rule.Delay > 0 || rule.RandomDelay > 0
+}
+
 // NoRedirect disables redirects for `github.com/gocolly/colly.Collector`.
 func NoRedirect() func(*colly.Collector) {
 	return func(c *colly.Collector) {
@@ -79,6 +322,16 @@ func NoRedirect() func(*colly.Collector) {
 	}
 }
 
+// RequestTimeout sets a timeout of requests for `github.com/gocolly/colly.Collector`.
+// The default timeout of the collector is used if the passed one is not positive.
+func RequestTimeout(timeout time.Duration) func(*colly.Collector) {
+	return func(c *colly.Collector) {
+		if timeout > 0 {
+			c.SetRequestTimeout(timeout)
+		}
+	}
+}
+
 // NoCookie disables cookie for `github.com/gocolly/colly.Collector`.
 func NoCookie() func(*colly.Collector) {
 	return func(c *colly.Collector) {
@@ -87,28 +340,74 @@ func NoCookie() func(*colly.Collector) {
 }
 
 // OnRequest registers a callback by `github.com/gocolly/colly.Collector.OnRequest()`.
-func OnRequest() func(*colly.Collector) {
+// Credentials are applied only to requests to their hosts.
+func OnRequest(credentials ...Credential) func(*colly.Collector) {
 	options := strings.Join(clickOptions, ";")
 	return func(c *colly.Collector) {
 		c.OnRequest(func(req *colly.Request) {
 			req.Headers.Set(clickOptHeader, options)
+			authorize(credentials, req.URL, *req.Headers)
+		})
+	}
+}
+
+// AbortExpired registers a callback by `github.com/gocolly/colly.Collector.OnRequest()`,
+// which aborts requests queued after the crawl timeout and reports them as skipped.
+func AbortExpired(bus EventBus, config CrawlerConfig) func(*colly.Collector) {
+	return func(c *colly.Collector) {
+		c.OnRequest(func(req *colly.Request) {
+			if config.limiter.expired(req.URL.String()) {
+				req.Abort()
+				bus <- SkipEvent{Location: req.URL.String(), Reason: TimeoutSkipReason}
+			}
 		})
 	}
 }
 
 // OnError registers a callback by `github.com/gocolly/colly.Collector.OnError()`.
-func OnError(bus EventBus) func(*colly.Collector) {
+// Retryable requests are sent again instead of reporting,
+// as well as requests rejected with a Retry-After header.
+// HEAD requests are sent again by GET if the method is not supported.
+// Redirect chains are traced if it's enabled by the config.
+func OnError(bus EventBus, config CrawlerConfig) func(*colly.Collector) {
 	return func(c *colly.Collector) {
+		retrier, tracer := newRetrier(config), newRedirectTracer(c, config)
 		c.OnError(func(resp *colly.Response, err error) {
+			if errors.Is(err, context.DeadlineExceeded) && config.limiter.expired(resp.Request.URL.String()) {
+				bus <- SkipEvent{Location: resp.Request.URL.String(), Reason: TimeoutSkipReason}
+				return
+			}
+			if resp.Request.Method == http.MethodHead &&
+				(resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+				if isRequested(c.Visit(resp.Request.URL.String())) {
+					return
+				}
+			}
+			if retrier.retry(resp, err) {
+				return
+			}
 			location, redirect := resp.Request.URL.String(), ""
 			if resp.Headers != nil {
 				redirect = resp.Headers.Get(locationHeader)
 			}
+			var (
+				hops            []Hop
+				loop, truncated bool
+			)
+			if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+				hops, loop, truncated = tracer.trace(resp.Request, redirect)
+			}
 			bus <- ErrorEvent{
-				StatusCode: resp.StatusCode,
-				Location:   location,
-				Redirect:   redirect,
-				Error:      err,
+				StatusCode:   resp.StatusCode,
+				Location:     location,
+				Redirect:     redirect,
+				Error:        err,
+				Timeout:      resp.StatusCode == 0 && isTimeout(err),
+				Attempts:     attempts(resp.Request),
+				Hops:         hops,
+				RedirectLoop: loop,
+
+				RedirectTruncated: truncated,
 			}
 		})
 	}
@@ -122,40 +421,282 @@ func OnResponse(bus EventBus) func(*colly.Collector) {
 			bus <- ResponseEvent{
 				StatusCode: resp.StatusCode,
 				Location:   location,
+				Attempts:   attempts(resp.Request),
 			}
 		})
 	}
 }
 
 // OnHTML registers a callback by `github.com/gocolly/colly.Collector.OnHTML()`.
-func OnHTML(base *url.URL, bus EventBus) func(*colly.Collector) {
-	isPage := func(current *url.URL) bool {
-		return current.Host == base.Host
-	}
+// Pages are walked within limits specified by the config,
+// links found on walked pages are always visited.
+// Assets found on walked pages are checked by HEAD requests, they are never walked.
+// Links to other hosts are handled in accordance with the external mode
+// and checked by the external collector, they are never walked.
+// Element ids and anchor names of internal pages are reported
+// if fragments verification is enabled.
+// Links excluded by URL filters are skipped as well as links disallowed by robots.txt
+// if the robots checker is passed.
+func OnHTML(
+	base *url.URL,
+	bus EventBus,
+	config CrawlerConfig,
+	external *colly.Collector,
+	robots *robotsChecker,
+) func(*colly.Collector) {
+	isPage := newSiteMatcher(base, config).contains
 	return func(c *colly.Collector) {
-		c.OnHTML("a[href]", func(el *colly.HTMLElement) {
-			if isPage(el.Request.URL) {
-				attr := el.Attr("href")
-				if strings.HasPrefix(attr, "#") {
-					return
-				}
-				href := el.Request.AbsoluteURL(attr)
-				if href == "" {
-					bus <- ProblemEvent{Message: "bad url", Context: struct {
-						Page string
-						Href string
-					}{el.Request.URL.String(), attr}}
-					return
-				}
-				if !strings.HasPrefix(href, "http") {
-					return
+		limiter := config.limiter
+		if limiter == nil {
+			limiter = newLimiter(config, bus)
+		}
+		checker, externalChecker := newHeadChecker(c), newHeadChecker(external)
+		handle := func(el *colly.HTMLElement, attr, kind string) {
+			if config.Fragments && kind == AnchorKind {
+				fragment(el, attr, isPage, bus)
+			}
+			if strings.HasPrefix(attr, "#") {
+				return
+			}
+			href := el.Request.AbsoluteURL(attr)
+			if href == "" {
+				bus <- ProblemEvent{Message: "bad url", Context: struct {
+					Page string
+					Href string
+				}{el.Request.URL.String(), attr}}
+				return
+			}
+			u, err := url.Parse(href)
+			if err != nil || !strings.HasPrefix(href, "http") {
+				return
+			}
+			if el.Request.URL.Scheme == "https" && u.Scheme == "http" {
+				bus <- insecure(el.Request.URL.String(), href, kind)
+			}
+			internal := isPage(u)
+			walk := WalkEvent{
+				Page:     el.Request.URL.String(),
+				Href:     href,
+				Kind:     kind,
+				Internal: internal,
+			}
+			switch {
+			case !internal && config.External == ExternalSkip:
+				return
+			case isExcluded(href, config):
+				bus <- SkipEvent{Location: href, Reason: ExcludedSkipReason}
+				bus <- walk
+				return
+			case !internal && config.External == ExternalList:
+				bus <- SkipEvent{Location: href, Reason: ExternalSkipReason}
+				bus <- walk
+				return
+			case !robots.allowed(u):
+				bus <- SkipEvent{Location: href, Reason: RobotsSkipReason}
+				bus <- walk
+				return
+			case !internal:
+				if externalChecker.check(href) {
+					bus <- walk
 				}
-				bus <- WalkEvent{
-					Page: el.Request.URL.String(),
-					Href: href,
+				return
+			}
+			if kind != AnchorKind {
+				if checker.check(href) {
+					bus <- walk
 				}
-				unsafe.Ignore(el.Request.Visit(href))
+				return
+			}
+			bus <- walk
+			unsafe.Ignore(el.Request.Visit(href))
+		}
+		c.OnHTML("a[href]", func(el *colly.HTMLElement) {
+			if isPage(el.Request.URL) && limiter.allow(el.Request) {
+				handle(el, el.Attr("href"), AnchorKind)
 			}
 		})
+		if config.Fragments {
+			c.OnHTML("html", func(el *colly.HTMLElement) {
+				if isPage(el.Request.URL) {
+					anchors := make([]string, 0, 8)
+					el.ForEach("[id], a[name]", func(_ int, el *colly.HTMLElement) {
+						if id := el.Attr("id"); id != "" {
+							anchors = append(anchors, id)
+						}
+						if name := el.Attr("name"); name != "" && el.Name == "a" {
+							anchors = append(anchors, name)
+						}
+					})
+					bus <- AnchorsEvent{Page: el.Request.URL.String(), Anchors: anchors}
+				}
+			})
+		}
+		for _, asset := range assets {
+			asset := asset
+			c.OnHTML(asset.selector, func(el *colly.HTMLElement) {
+				if isPage(el.Request.URL) && limiter.allow(el.Request) {
+					for _, ref := range asset.references(el) {
+						handle(el, ref, asset.kind)
+					}
+				}
+			})
+		}
+	}
+}
+
+// insecure returns a problem of an http link located on an https page:
+// anchors lead to insecure pages, other kinds of links cause mixed content.
+func insecure(page, href, kind string) ProblemEvent {
+	problem := ProblemEvent{Category: InsecureLinkProblem, Message: "insecure link", Context: struct {
+		Page string
+		Href string
+	}{page, href}}
+	if kind != AnchorKind {
+		problem.Category, problem.Message = MixedContentProblem, "mixed content: "+kind
+	}
+	return problem
+}
+
+// fragment reports a reference to a fragment of an internal page.
+func fragment(el *colly.HTMLElement, attr string, isPage func(*url.URL) bool, bus EventBus) {
+	ref, err := url.Parse(attr)
+	if err != nil || ref.Fragment == "" {
+		return
+	}
+	target := el.Request.URL.ResolveReference(ref)
+	if !isPage(target) {
+		return
+	}
+	target.Fragment = ""
+	bus <- FragmentEvent{
+		Page:     el.Request.URL.String(),
+		Href:     target.String(),
+		Fragment: ref.Fragment,
+	}
+}
+
+func newHeadChecker(collector *colly.Collector) *headChecker {
+	return &headChecker{collector: collector, checked: make(map[string]bool)}
+}
+
+// headChecker sends a HEAD request for each link only once.
+type headChecker struct {
+	mu        sync.Mutex
+	collector *colly.Collector
+	checked   map[string]bool
+}
+
+// check returns true if the link is already checked or it's requested now.
+func (ch *headChecker) check(href string) bool {
+	if ch.collector == nil {
+		return false
+	}
+	ch.mu.Lock()
+	if ch.checked[href] {
+		ch.mu.Unlock()
+		return true
+	}
+	ch.checked[href] = true
+	ch.mu.Unlock()
+	return isRequested(ch.collector.Head(href))
+}
+
+func newLimiter(config CrawlerConfig, bus EventBus) *limiter {
+	l := &limiter{
+		bus:      bus,
+		maxDepth: config.MaxDepth,
+		maxPages: config.MaxPages,
+		pages:    make(map[string]struct{}),
+		reached:  make(map[string]bool),
+	}
+	if config.Timeout > 0 {
+		l.deadline = time.Now().Add(config.Timeout)
+	}
+	return l
+}
+
+type limiter struct {
+	mu       sync.Mutex
+	bus      EventBus
+	maxDepth int
+	maxPages int
+	deadline time.Time
+	pages    map[string]struct{}
+	reached  map[string]bool
+}
+
+// allow checks that the page of the request can be walked
+// and reports the first time when a limit is reached.
+func (l *limiter) allow(req *colly.Request) bool {
+	page := req.URL.String()
+	if l.expired(page) {
+		return false
+	}
+	allowed, problem := l.walk(page, req.Depth)
+	if problem != nil {
+		l.bus <- *problem
+	}
+	return allowed
+}
+
+// walk registers the page and returns false if a limit is reached,
+// with the problem if it's reached the first time.
+func (l *limiter) walk(page string, depth int) (bool, *ProblemEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.maxDepth > 0 && depth > l.maxDepth {
+		return false, l.reach("max depth limit reached", page)
+	}
+	if _, walked := l.pages[page]; walked {
+		return true, nil
+	}
+	if l.maxPages > 0 && len(l.pages) >= l.maxPages {
+		return false, l.reach("max pages limit reached", page)
+	}
+	l.pages[page] = struct{}{}
+	return true, nil
+}
+
+// expired checks that the crawl timeout is exceeded
+// and reports the first time when it happens.
+func (l *limiter) expired(page string) bool {
+	if l == nil || l.deadline.IsZero() || time.Now().Before(l.deadline) {
+		return false
+	}
+	l.mu.Lock()
+	problem := l.reach("crawl timeout exceeded", page)
+	l.mu.Unlock()
+	if problem != nil {
+		l.bus <- *problem
+	}
+	return true
+}
+
+// reach returns the problem of the limit only the first time it's reached.
+// It's called under the lock, the problem is sent after the lock is released,
+// so a full event bus doesn't block checks of the limits.
+func (l *limiter) reach(limit, page string) *ProblemEvent {
+	if l.reached[limit] {
+		return nil
+	}
+	l.reached[limit] = true
+	return &ProblemEvent{Message: limit + ", the report is partial", Context: struct {
+		Page string
+	}{page}}
+}
+
+// isRequested returns false if the error is returned by the collector
+// before a request is sent, in this case no response events are expected.
+func isRequested(err error) bool {
+	switch err {
+	case colly.ErrMissingURL,
+		colly.ErrMaxDepth,
+		colly.ErrForbiddenURL,
+		colly.ErrNoURLFiltersMatch,
+		colly.ErrForbiddenDomain,
+		colly.ErrRobotsTxtBlocked,
+		colly.ErrQueueFull:
+		return false
 	}
+	return true
 }
@@ -1,11 +1,21 @@
 package availability_test
 
 import (
+	"bytes"
+	"encoding/pem"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.octolab.org/unsafe"
 
 	"github.com/kamilsk/check/http/availability"
 )
@@ -13,37 +23,31 @@ import (
 func TestCrawlerColly(t *testing.T) {
 	site, closer := site()
 	defer closer()
-	{
+	for _, config := range []availability.CrawlerConfig{
+		{UserAgent: "test/dev", Verbose: true, Output: ioutil.Discard},
+		{UserAgent: "test/dev", Verbose: true, Output: ioutil.Discard, Concurrency: 4, PerHost: 2},
+	} {
 		var errorEvents, redirectEvents, responseEvents, walkEvents, problemEvents, unknownEvents int
-		wg, bus := &sync.WaitGroup{}, availability.NewReadableEventBus(8)
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for event := range bus {
-				switch e := event.(type) {
-				case availability.ErrorEvent:
-					errorEvents++
-					if e.Redirect != "" {
-						redirectEvents++
-					}
-				case availability.ResponseEvent:
-					responseEvents++
-				case availability.WalkEvent:
-					walkEvents++
-				case availability.ProblemEvent:
-					problemEvents++
-				default:
-					unknownEvents++
+		crawler := availability.CrawlerColly(config)
+		events, err := collect(crawler, site.URL+"/")
+		assert.NoError(t, err)
+		for _, event := range events {
+			switch e := event.(type) {
+			case availability.ErrorEvent:
+				errorEvents++
+				if e.Redirect != "" {
+					redirectEvents++
 				}
+			case availability.ResponseEvent:
+				responseEvents++
+			case availability.WalkEvent:
+				walkEvents++
+			case availability.ProblemEvent:
+				problemEvents++
+			default:
+				unknownEvents++
 			}
-		}()
-		crawler := availability.CrawlerColly(availability.CrawlerConfig{
-			UserAgent: "test/dev",
-			Verbose:   true,
-			Output:    ioutil.Discard,
-		})
-		assert.NoError(t, crawler.Visit(site.URL+"/", bus))
-		wg.Wait()
+		}
 		assert.Equal(t, 28, errorEvents)
 		assert.Equal(t, 10, redirectEvents)
 		assert.Equal(t, 8, responseEvents)
@@ -51,8 +55,445 @@ func TestCrawlerColly(t *testing.T) {
 		assert.Equal(t, 1, problemEvents)
 		assert.Empty(t, unknownEvents)
 	}
+	{
+		var walkEvents int
+		var problems []string
+		crawler := availability.CrawlerColly(availability.CrawlerConfig{Timeout: time.Nanosecond})
+		events, err := collect(crawler, site.URL+"/")
+		assert.NoError(t, err)
+		for _, event := range events {
+			switch e := event.(type) {
+			case availability.WalkEvent:
+				walkEvents++
+			case availability.ProblemEvent:
+				problems = append(problems, e.Message)
+			}
+		}
+		assert.Empty(t, walkEvents)
+		assert.Equal(t, []string{"crawl timeout exceeded, the report is partial"}, problems)
+	}
 	{
 		crawler := availability.CrawlerColly(availability.CrawlerConfig{})
 		assert.Error(t, crawler.Visit(":bad", make(availability.EventBus)))
 	}
 }
+
+func TestCrawlerColly_limits(t *testing.T) {
+	// each page links to the next one: / -> /1 -> /2 -> /3 -> ...
+	ladder := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		step, _ := strconv.Atoi(strings.Trim(req.URL.Path, "/"))
+		unsafe.Ignore(tpl.Execute(rw, []struct {
+			Href string
+			Text string
+		}{{Href: fmt.Sprintf("/%d", step+1), Text: "next"}}))
+	}))
+	defer ladder.Close()
+
+	tests := []struct {
+		name     string
+		config   availability.CrawlerConfig
+		walks    int
+		problems []string
+	}{
+		{"max depth", availability.CrawlerConfig{MaxDepth: 3, MaxPages: 5},
+			3, []string{"max depth limit reached, the report is partial"}},
+		{"max pages", availability.CrawlerConfig{MaxDepth: 5, MaxPages: 2},
+			2, []string{"max pages limit reached, the report is partial"}},
+	}
+	for _, test := range tests {
+		tc := test
+		t.Run(test.name, func(t *testing.T) {
+			var walkEvents int
+			var problems []string
+			events, err := collect(availability.CrawlerColly(tc.config), ladder.URL+"/")
+			assert.NoError(t, err)
+			for _, event := range events {
+				switch e := event.(type) {
+				case availability.WalkEvent:
+					walkEvents++
+				case availability.ProblemEvent:
+					problems = append(problems, e.Message)
+				}
+			}
+			assert.Equal(t, tc.walks, walkEvents)
+			assert.Equal(t, tc.problems, problems)
+		})
+	}
+}
+
+func TestCrawlerColly_timeout(t *testing.T) {
+	const pages = 100
+	// the entry page links to all others, each page responds slowly
+	wide := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		type link = struct {
+			Href string
+			Text string
+		}
+		links := make([]link, 0, pages)
+		if req.URL.Path == "/" {
+			for i := 1; i <= pages; i++ {
+				links = append(links, link{Href: fmt.Sprintf("/%d", i), Text: "page"})
+			}
+		} else {
+			time.Sleep(50 * time.Millisecond)
+		}
+		unsafe.Ignore(tpl.Execute(rw, links))
+	}))
+	defer wide.Close()
+
+	tests := map[string]availability.CrawlerConfig{
+		"sync":  {Timeout: 200 * time.Millisecond},
+		"async": {Timeout: 200 * time.Millisecond, Concurrency: 4},
+	}
+	for name, config := range tests {
+		config := config
+		t.Run(name, func(t *testing.T) {
+			var responses int
+			var problems []string
+			walks, reported := make(map[string]bool), make(map[string]bool)
+			start := time.Now()
+			events, err := collect(availability.CrawlerColly(config), wide.URL+"/")
+			assert.NoError(t, err)
+			for _, event := range events {
+				switch e := event.(type) {
+				case availability.ResponseEvent:
+					responses++
+					reported[e.Location] = true
+				case availability.ErrorEvent:
+					reported[e.Location] = true
+				case availability.SkipEvent:
+					reported[e.Location] = true
+				case availability.WalkEvent:
+					walks[e.Href] = true
+				case availability.ProblemEvent:
+					problems = append(problems, e.Message)
+				}
+			}
+			assert.Less(t, int64(time.Since(start)), int64(time.Second))
+			assert.Less(t, responses, pages)
+			assert.Equal(t, []string{"crawl timeout exceeded, the report is partial"}, problems)
+			for href := range walks {
+				assert.True(t, reported[href], href)
+			}
+		})
+	}
+}
+
+func TestCrawlerColly_external(t *testing.T) {
+	var mu sync.Mutex
+	requests := make([]string, 0, 4)
+	external := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		requests = append(requests, req.Method+" "+req.URL.Path)
+		mu.Unlock()
+		if req.URL.Path == "/head" && req.Method == http.MethodHead {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer external.Close()
+	site := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		unsafe.Ignore(tpl.Execute(rw, []struct {
+			Href string
+			Text string
+		}{
+			{Href: external.URL + "/", Text: "external"},
+			{Href: external.URL + "/head", Text: "HEAD is not allowed"},
+			{Href: external.URL + "/", Text: "duplicate"},
+		}))
+	}))
+	defer site.Close()
+
+	tests := []struct {
+		name     string
+		mode     string
+		requests []string
+		statuses map[string]int
+		skipped  map[string]string
+		walks    int
+	}{
+		{
+			"check",
+			availability.ExternalCheck,
+			[]string{"HEAD /", "HEAD /head", "GET /head"},
+			map[string]int{site.URL + "/": 200, external.URL + "/": 200, external.URL + "/head": 200},
+			map[string]string{},
+			3,
+		},
+		{
+			"skip",
+			availability.ExternalSkip,
+			[]string{},
+			map[string]int{site.URL + "/": 200},
+			map[string]string{},
+			0,
+		},
+		{
+			"list",
+			availability.ExternalList,
+			[]string{},
+			map[string]int{site.URL + "/": 200},
+			map[string]string{
+				external.URL + "/":     availability.ExternalSkipReason,
+				external.URL + "/head": availability.ExternalSkipReason,
+			},
+			3,
+		},
+	}
+	for _, test := range tests {
+		tc := test
+		t.Run(test.name, func(t *testing.T) {
+			requests = requests[:0]
+			statuses, skipped, walks := make(map[string]int), make(map[string]string), 0
+			crawler := availability.CrawlerColly(availability.CrawlerConfig{External: tc.mode})
+			events, err := collect(crawler, site.URL+"/")
+			assert.NoError(t, err)
+			for _, event := range events {
+				switch e := event.(type) {
+				case availability.ErrorEvent:
+					statuses[e.Location] = e.StatusCode
+				case availability.ResponseEvent:
+					statuses[e.Location] = e.StatusCode
+				case availability.SkipEvent:
+					skipped[e.Location] = e.Reason
+				case availability.WalkEvent:
+					walks++
+				}
+			}
+			assert.Equal(t, tc.requests, requests)
+			assert.Equal(t, tc.statuses, statuses)
+			assert.Equal(t, tc.skipped, skipped)
+			assert.Equal(t, tc.walks, walks)
+		})
+	}
+}
+
+func TestCrawlerColly_externalConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, peak, requested int
+	external := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		inFlight++
+		requested++
+		if inFlight > peak {
+			peak = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer external.Close()
+	const pages = 20
+	site := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		type link = struct {
+			Href string
+			Text string
+		}
+		links := make([]link, 0, pages)
+		if req.URL.Path == "/" {
+			for i := 0; i < pages; i++ {
+				links = append(links, link{Href: "/" + strconv.Itoa(i), Text: "page"})
+			}
+		} else {
+			links = append(links, link{Href: external.URL + req.URL.Path, Text: "external"})
+		}
+		unsafe.Ignore(tpl.Execute(rw, links))
+	}))
+	defer site.Close()
+
+	tests := map[string]struct {
+		concurrency int
+		limit       int
+	}{
+		"default":   {0, 1},
+		"exclusive": {1, 1},
+		"shared":    {3, 3},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			mu.Lock()
+			peak, requested = 0, 0
+			mu.Unlock()
+			report := availability.NewReport(availability.CrawlerForSites(availability.CrawlerColly(
+				availability.CrawlerConfig{Concurrency: 8, ExternalConcurrency: test.concurrency},
+			))).For([]string{site.URL + "/"}).Fill()
+			for site := range report.Sites() {
+				assert.NoError(t, site.Error)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Equal(t, pages, requested)
+			assert.LessOrEqual(t, peak, test.limit)
+		})
+	}
+}
+
+func TestCrawlerColly_fragments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/":
+			unsafe.DoSilent(io.WriteString(rw, `<!doctype html>
+<html lang="en">
+<body>
+<h1 id="intro">Intro</h1>
+<a href="#intro">intro</a>
+<a href="#top">top</a>
+<a href="#deleted">deleted</a>
+<a href="/docs#install">install</a>
+<a href="/docs#removed">removed</a>
+<a href="/docs#removed">removed again</a>
+</body>
+</html>`))
+		case "/docs":
+			unsafe.DoSilent(io.WriteString(rw, `<!doctype html>
+<html lang="en">
+<body>
+<a name="install">Install</a>
+<a href="/#intro">intro</a>
+</body>
+</html>`))
+		}
+	}))
+	defer server.Close()
+
+	report := availability.NewReport(availability.CrawlerForSites(
+		availability.CrawlerColly(availability.CrawlerConfig{Fragments: true}),
+	)).For([]string{server.URL + "/"}).Fill()
+	broken := make(map[string][]string)
+	for site := range report.Sites() {
+		assert.NoError(t, site.Error)
+		for _, ref := range site.References() {
+			if ref.BrokenAnchor != "" {
+				assert.Equal(t, availability.AnchorCategory, ref.Category())
+				assert.Equal(t, http.StatusOK, ref.StatusCode)
+				broken[ref.Location] = ref.Pages
+			}
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		server.URL + "/#deleted":     {server.URL + "/"},
+		server.URL + "/docs#removed": {server.URL + "/"},
+	}, broken)
+}
+
+func TestCrawlerColly_insecure(t *testing.T) {
+	insecure := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer insecure.Close()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/" {
+			rw.WriteHeader(http.StatusOK)
+			return
+		}
+		unsafe.DoSilent(fmt.Fprintf(rw, `<!doctype html>
+<html lang="en">
+<body>
+<a href="/secure">secure</a>
+<a href="%[1]s/page">insecure</a>
+<a href="%[1]s/page">insecure again</a>
+<img src="%[1]s/image.png" alt="image">
+<script src="%[1]s/script.js"></script>
+</body>
+</html>`, insecure.URL))
+	}))
+	defer server.Close()
+	pool, err := availability.LoadCertificates(bytes.NewReader(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.Certificate().Raw,
+	})))
+	assert.NoError(t, err)
+
+	check := func() *availability.Report {
+		return availability.NewReport(availability.CrawlerForSites(
+			availability.CrawlerColly(availability.CrawlerConfig{RootCAs: pool}),
+		)).For([]string{server.URL + "/"}).Fill()
+	}
+	problems := make(map[string][]string)
+	for site := range check().Sites() {
+		assert.NoError(t, site.Error)
+		for _, problem := range site.Problems {
+			problems[problem.Category] = append(problems[problem.Category], fmt.Sprintf("%+v", problem.Context))
+		}
+	}
+	context := func(href string) string { return fmt.Sprintf("{Page:%s/ Href:%s}", server.URL, href) }
+	assert.Equal(t, []string{context(insecure.URL + "/page")}, problems[availability.InsecureLinkProblem])
+	assert.ElementsMatch(t, []string{
+		context(insecure.URL + "/image.png"),
+		context(insecure.URL + "/script.js"),
+	}, problems[availability.MixedContentProblem])
+
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(t, availability.NewPrinter(availability.OutputForPrinting(buf)).For(check()).Print())
+	assert.Contains(t, buf.String(), "found insecure links on https pages of the site")
+	assert.Contains(t, buf.String(), "found mixed content on https pages of the site")
+	assert.NotContains(t, buf.String(), "found problems on the site")
+}
+
+func TestCrawlerColly_throttle(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, peak int
+	hostInFlight, hostPeak := make(map[string]int), make(map[string]int)
+	const pages = 20
+	serve := func() *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			mu.Lock()
+			inFlight++
+			hostInFlight[req.Host]++
+			if inFlight > peak {
+				peak = inFlight
+			}
+			if hostInFlight[req.Host] > hostPeak[req.Host] {
+				hostPeak[req.Host] = hostInFlight[req.Host]
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			inFlight--
+			hostInFlight[req.Host]--
+			mu.Unlock()
+			type link = struct {
+				Href string
+				Text string
+			}
+			links := make([]link, 0, pages)
+			if req.URL.Path == "/" {
+				for i := 0; i < pages; i++ {
+					links = append(links, link{Href: "/" + strconv.Itoa(i), Text: "page"})
+				}
+			}
+			unsafe.Ignore(tpl.Execute(rw, links))
+		}))
+	}
+	first, second := serve(), serve()
+	defer first.Close()
+	defer second.Close()
+
+	throttle := availability.NewThrottle(availability.CrawlerConfig{Concurrency: 3, PerHost: 2})
+	report := availability.NewReport(
+		availability.CrawlerForSite(first.URL+"/", availability.CrawlerColly(
+			availability.CrawlerConfig{Concurrency: 8, Throttle: throttle},
+		)),
+		availability.CrawlerForSite(second.URL+"/", availability.CrawlerColly(
+			availability.CrawlerConfig{Concurrency: 8, PerHost: 1, Throttle: throttle},
+		)),
+		availability.ConcurrentSites(2),
+	).For([]string{first.URL + "/", second.URL + "/"}).Fill()
+	for site := range report.Sites() {
+		assert.NoError(t, site.Error)
+		if assert.Len(t, site.Pages, 1) {
+			assert.Len(t, site.Pages[0].Links, pages)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	assert.LessOrEqual(t, peak, 3)
+	assert.Len(t, hostPeak, 2)
+	assert.LessOrEqual(t, hostPeak[strings.TrimPrefix(first.URL, "http://")], 2)
+	assert.Equal(t, 1, hostPeak[strings.TrimPrefix(second.URL, "http://")])
+}
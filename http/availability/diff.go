@@ -0,0 +1,229 @@
+package availability
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"go.octolab.org/unsafe"
+
+	"github.com/kamilsk/check/errors"
+)
+
+// Compare returns changes between two reports, websites are matched by their names.
+// Websites missing from one of the reports are compared with empty ones.
+func Compare(before, after []Site) Diff {
+	index := make(map[string]int, len(before))
+	for i, site := range before {
+		index[site.Name] = i
+	}
+	diff := make(Diff, 0, len(after))
+	matched := make(map[string]bool, len(after))
+	for _, site := range after {
+		var origin Site
+		if i, exists := index[site.Name]; exists {
+			origin = before[i]
+		}
+		matched[site.Name] = true
+		diff = append(diff, compareSites(origin, site))
+	}
+	for _, site := range before {
+		if !matched[site.Name] {
+			diff = append(diff, compareSites(site, Site{Name: site.Name}))
+		}
+	}
+	return diff
+}
+
+// Diff contains changes between two reports grouped by websites.
+type Diff []SiteDiff
+
+// SiteDiff contains changes of a website between two reports.
+type SiteDiff struct {
+	Name string
+	// Added contains pages missing from the previous report.
+	Added []Link
+	// Removed contains pages missing from the next report.
+	Removed []Link
+	// Changed contains links with a changed status or redirect target.
+	Changed []Change
+}
+
+// Empty returns true if the website has no changes.
+func (diff SiteDiff) Empty() bool {
+	return len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0
+}
+
+// Change contains states of a link in two reports.
+type Change struct {
+	Before Link
+	After  Link
+}
+
+// Redirected returns true if the link redirects to a new target.
+func (change Change) Redirected() bool {
+	return change.After.Redirect != "" && change.After.Redirect != change.Before.Redirect
+}
+
+// String returns a description of the change, e.g.
+// "[200 -> 404] https://example.com/" or "[301] http://example.com/ -> https://example.com/ (was https://example.org/)".
+func (change Change) String() string {
+	before, after := change.Before, change.After
+	status := fmt.Sprintf("%d", after.StatusCode)
+	if before.StatusCode != after.StatusCode {
+		status = fmt.Sprintf("%d -> %d", before.StatusCode, after.StatusCode)
+	}
+	description := fmt.Sprintf("[%s] %s", status, after.Location)
+	if after.Error != nil {
+		description += fmt.Sprintf(" -> (%s)", after.Error)
+	}
+	if after.Redirect != "" {
+		description += " -> " + after.Redirect
+		if change.Redirected() && before.Redirect != "" {
+			description += fmt.Sprintf(" (was %s)", before.Redirect)
+		}
+	}
+	return description
+}
+
+func compareSites(before, after Site) SiteDiff {
+	diff := SiteDiff{Name: after.Name}
+	pages := func(site Site) map[string]Link {
+		index := make(map[string]Link, len(site.Pages))
+		for _, page := range site.Pages {
+			if page.Link != nil && page.Location != "" {
+				index[page.Location] = *page.Link
+			}
+		}
+		return index
+	}
+	previous, next := pages(before), pages(after)
+	for location, page := range next {
+		if _, exists := previous[location]; !exists {
+			diff.Added = append(diff.Added, page)
+		}
+	}
+	for location, page := range previous {
+		if _, exists := next[location]; !exists {
+			diff.Removed = append(diff.Removed, page)
+		}
+	}
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].Location < diff.Added[j].Location })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].Location < diff.Removed[j].Location })
+
+	links := make(map[string]Link)
+	for _, ref := range before.References() {
+		links[ref.Location] = ref.Link
+	}
+	for _, ref := range after.References() {
+		link, exists := links[ref.Location]
+		if !exists || ref.Skipped != "" || link.Skipped != "" {
+			continue
+		}
+		if link.StatusCode != ref.StatusCode || link.Redirect != ref.Redirect || link.Category() != ref.Category() {
+			diff.Changed = append(diff.Changed, Change{Before: link, After: ref.Link})
+		}
+	}
+	return diff
+}
+
+// PrintDiff prints changes between two reports into the configured output.
+// Stdout is used as a fallback if the output is not set up.
+func (p *Printer) PrintDiff(diff Diff) error {
+	w := p.outOrStdout()
+	switch p.format {
+	case "", TextFormat:
+		return p.printDiffText(w, diff)
+	case JSONFormat:
+		return p.printDiffJSON(w, diff)
+	default:
+		return errors.Errorf("unsupported output format %q of the diff", p.format)
+	}
+}
+
+func (p *Printer) printDiffText(w io.Writer, diff Diff) error {
+	var blob = [1024]byte{}
+	buf := bytes.NewBuffer(blob[:0])
+	for _, site := range diff {
+		if site.Empty() {
+			continue
+		}
+		p.critical().Fprintf(w, "%s\n", site.Name)
+		lines := make([]diffLine, 0, len(site.Added)+len(site.Removed)+len(site.Changed))
+		for _, group := range []struct {
+			title string
+			links []Link
+		}{{"added", site.Added}, {"removed", site.Removed}} {
+			for _, link := range group.links {
+				link := link
+				{
+					buf.Reset()
+					unsafe.Ignore(p.tpl.Execute(buf, link))
+				}
+				lines = append(lines, diffLine{&link, fmt.Sprintf("(%s) %s", group.title, buf.String())})
+			}
+		}
+		for _, change := range site.Changed {
+			change := change
+			lines = append(lines, diffLine{&change.After, "(changed) " + change.String()})
+		}
+		last := len(lines) - 1
+		for i, line := range lines {
+			if i == last {
+				p.typewriter(line.link).Fprintf(w, "    └───%s\n", p.decoder(line.text))
+				continue
+			}
+			p.typewriter(line.link).Fprintf(w, "    ├───%s\n", p.decoder(line.text))
+		}
+	}
+	return nil
+}
+
+func (p *Printer) printDiffJSON(w io.Writer, diff Diff) error {
+	encoded := make([]jsonSiteDiff, 0, len(diff))
+	for _, site := range diff {
+		siteDiff := jsonSiteDiff{
+			Name:    site.Name,
+			Added:   make([]jsonLink, 0, len(site.Added)),
+			Removed: make([]jsonLink, 0, len(site.Removed)),
+			Changed: make([]jsonChange, 0, len(site.Changed)),
+		}
+		for _, link := range site.Added {
+			siteDiff.Added = append(siteDiff.Added, encodeLink(link))
+		}
+		for _, link := range site.Removed {
+			siteDiff.Removed = append(siteDiff.Removed, encodeLink(link))
+		}
+		for _, change := range site.Changed {
+			siteDiff.Changed = append(siteDiff.Changed, jsonChange{
+				Before:     encodeLink(change.Before),
+				After:      encodeLink(change.After),
+				Redirected: change.Redirected(),
+			})
+		}
+		encoded = append(encoded, siteDiff)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(encoded)
+}
+
+type diffLine struct {
+	link *Link
+	text string
+}
+
+type jsonSiteDiff struct {
+	Name    string       `json:"name"`
+	Added   []jsonLink   `json:"added"`
+	Removed []jsonLink   `json:"removed"`
+	Changed []jsonChange `json:"changed"`
+}
+
+type jsonChange struct {
+	Before     jsonLink `json:"before"`
+	After      jsonLink `json:"after"`
+	Redirected bool     `json:"redirected,omitempty"`
+}
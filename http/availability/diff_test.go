@@ -0,0 +1,71 @@
+package availability_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kamilsk/check/http/availability"
+)
+
+func TestCompare(t *testing.T) {
+	before, err := availability.LoadSites(strings.NewReader(`[
+{"name":"test.dev","pages":[
+	{"status_code":200,"location":"http://test.dev/","internal":true,"links":[
+		{"status_code":200,"location":"http://test.dev/old/","internal":true},
+		{"status_code":200,"location":"http://test.dev/broken/","internal":true},
+		{"status_code":301,"location":"http://test.dev/moved/","redirect":"http://test.dev/old/","internal":true},
+		{"status_code":200,"location":"http://stable.dev/","internal":false}
+	]},
+	{"status_code":200,"location":"http://test.dev/old/","internal":true,"links":[]}
+],"problems":[]},
+{"name":"gone.dev","pages":[{"status_code":200,"location":"http://gone.dev/","internal":true,"links":[]}],"problems":[]}
+]`))
+	assert.NoError(t, err)
+	after, err := availability.LoadSites(strings.NewReader(`[
+{"name":"test.dev","pages":[
+	{"status_code":200,"location":"http://test.dev/","internal":true,"links":[
+		{"status_code":200,"location":"http://test.dev/new/","internal":true},
+		{"status_code":404,"location":"http://test.dev/broken/","internal":true},
+		{"status_code":301,"location":"http://test.dev/moved/","redirect":"http://test.dev/new/","internal":true},
+		{"status_code":200,"location":"http://stable.dev/","internal":false}
+	]},
+	{"status_code":200,"location":"http://test.dev/new/","internal":true,"links":[]}
+],"problems":[]}
+]`))
+	assert.NoError(t, err)
+
+	diff := availability.Compare(before, after)
+	assert.Len(t, diff, 2)
+	assert.Len(t, diff[0].Changed, 2)
+	assert.True(t, diff[0].Changed[1].Redirected())
+
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(t, availability.NewPrinter(availability.OutputForPrinting(buf)).PrintDiff(diff))
+	assert.Equal(t, `test.dev
+    ├───(added) [200] http://test.dev/new/
+    ├───(removed) [200] http://test.dev/old/
+    ├───(changed) [200 -> 404] http://test.dev/broken/
+    └───(changed) [301] http://test.dev/moved/ -> http://test.dev/new/ (was http://test.dev/old/)
+gone.dev
+    └───(removed) [200] http://gone.dev/
+`, buf.String())
+
+	buf.Reset()
+	assert.NoError(t, availability.NewPrinter(
+		availability.FormatOutput(availability.JSONFormat),
+		availability.OutputForPrinting(buf),
+	).PrintDiff(diff))
+	assert.Contains(t, buf.String(), `"name": "gone.dev"`)
+	assert.Contains(t, buf.String(), `"redirected": true`)
+
+	assert.Error(t, availability.NewPrinter(
+		availability.FormatOutput(availability.JUnitFormat),
+		availability.OutputForPrinting(buf),
+	).PrintDiff(diff))
+
+	_, err = availability.LoadSites(strings.NewReader(`{}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,98 @@
+package availability
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+
+	"github.com/kamilsk/check/errors"
+)
+
+// ExcludedSkipReason is a reason of not requested links excluded by URL filters.
+const ExcludedSkipReason = "excluded"
+
+// regexpPrefix marks a URL pattern as a regular expression, otherwise it's a glob.
+const regexpPrefix = "re:"
+
+// CompilePatterns converts URL patterns into regular expressions.
+// A pattern prefixed by "re:" is a regular expression, e.g. "re:[?&]date=",
+// otherwise it's a glob matched against a whole URL, where "*" matches
+// any sequence of characters and "?" matches a single one, e.g. "*/logout*".
+func CompilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		expr := globToRegexp(pattern)
+		if strings.HasPrefix(pattern, regexpPrefix) {
+			expr = strings.TrimPrefix(pattern, regexpPrefix)
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "compile URL pattern %q", pattern)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// FilterURLs sets URL filters for `github.com/gocolly/colly.Collector`.
+func FilterURLs(include, exclude []*regexp.Regexp) func(*colly.Collector) {
+	return func(c *colly.Collector) {
+		c.URLFilters = include
+		c.DisallowedURLFilters = exclude
+	}
+}
+
+// IgnoreURLs sets URL filters of links which are checked but ignored by the report,
+// they are not printed, except of the verbose mode, and don't fail a check.
+func IgnoreURLs(patterns []*regexp.Regexp) func(*Report) {
+	return func(r *Report) {
+		r.ignore = patterns
+	}
+}
+
+// ignore marks links of the website matched one of the filters as ignored.
+func ignore(site *Site, filters []*regexp.Regexp) {
+	if len(filters) == 0 {
+		return
+	}
+	mark := func(links []Link) {
+		for i := range links {
+			links[i].Ignored = matchesAny(links[i].Location, filters)
+		}
+	}
+	for _, page := range site.Pages {
+		if page.Link != nil {
+			page.Link.Ignored = matchesAny(page.Location, filters)
+		}
+		mark(page.Links)
+	}
+	mark(site.Orphans)
+	mark(site.Unlisted)
+	mark(site.Fixed)
+}
+
+// isExcluded returns true if the link doesn't match any of include filters
+// or matches one of exclude filters.
+func isExcluded(href string, config CrawlerConfig) bool {
+	if len(config.Include) > 0 && !matchesAny(href, config.Include) {
+		return true
+	}
+	return matchesAny(href, config.Exclude)
+}
+
+func matchesAny(href string, filters []*regexp.Regexp) bool {
+	for _, filter := range filters {
+		if filter.MatchString(href) {
+			return true
+		}
+	}
+	return false
+}
+
+func globToRegexp(glob string) string {
+	expr := regexp.QuoteMeta(glob)
+	expr = strings.Replace(expr, `\*`, `.*`, -1)
+	expr = strings.Replace(expr, `\?`, `.`, -1)
+	return "^" + expr + "$"
+}
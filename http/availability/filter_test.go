@@ -0,0 +1,142 @@
+package availability_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.octolab.org/unsafe"
+
+	"github.com/kamilsk/check/http/availability"
+)
+
+func TestCompilePatterns(t *testing.T) {
+	filters, err := availability.CompilePatterns([]string{"*/logout*", "re:[?&]date="})
+	assert.NoError(t, err)
+	assert.Len(t, filters, 2)
+	assert.True(t, filters[0].MatchString("https://kamil.samigullin.info/logout?next=/"))
+	assert.False(t, filters[0].MatchString("https://kamil.samigullin.info/login"))
+	assert.True(t, filters[1].MatchString("https://kamil.samigullin.info/calendar?view=day&date=2020-01-01"))
+
+	_, err = availability.CompilePatterns([]string{"re:["})
+	assert.Error(t, err)
+}
+
+func TestCrawlerColly_filters(t *testing.T) {
+	var excluded int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/":
+			unsafe.Ignore(tpl.Execute(rw, []struct {
+				Href string
+				Text string
+			}{
+				{Href: "/public", Text: "public"},
+				{Href: "/logout", Text: "logout"},
+				{Href: "/calendar?date=2020-01-01", Text: "calendar"},
+			}))
+		case "/public":
+			rw.WriteHeader(http.StatusOK)
+		default:
+			atomic.AddInt32(&excluded, 1)
+			rw.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	exclude, err := availability.CompilePatterns([]string{"*/logout", "re:[?&]date="})
+	assert.NoError(t, err)
+	skipped := make(map[string]string)
+	crawler := availability.CrawlerColly(availability.CrawlerConfig{Exclude: exclude})
+	events, err := collect(crawler, server.URL+"/")
+	assert.NoError(t, err)
+	for _, event := range events {
+		if e, is := event.(availability.SkipEvent); is {
+			skipped[e.Location] = e.Reason
+		}
+	}
+	assert.Equal(t, map[string]string{
+		server.URL + "/logout":                   availability.ExcludedSkipReason,
+		server.URL + "/calendar?date=2020-01-01": availability.ExcludedSkipReason,
+	}, skipped)
+	assert.Empty(t, atomic.LoadInt32(&excluded))
+
+	t.Run("excluded entry point", func(t *testing.T) {
+		include, err := availability.CompilePatterns([]string{"*/public"})
+		assert.NoError(t, err)
+		crawler := availability.CrawlerColly(availability.CrawlerConfig{Include: include})
+		assert.Error(t, crawler.Visit(server.URL+"/", availability.NewReadableEventBus(8)))
+	})
+}
+
+func TestPrinter_showExcluded(t *testing.T) {
+	site := availability.Site{Pages: []*availability.Page{
+		{
+			&availability.Link{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/"},
+			[]availability.Link{
+				{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/en/"},
+				{Location: "https://kamil.samigullin.info/logout", Skipped: availability.ExcludedSkipReason},
+			},
+		},
+	}}
+	report := func() availability.Reporter {
+		m := &PrinterMock{}
+		data := make(chan availability.Site, 1)
+		data <- site
+		close(data)
+		var pipe <-chan availability.Site = data
+		m.On("Sites").Return(pipe)
+		return m
+	}
+
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(t, availability.NewPrinter(availability.OutputForPrinting(buf)).For(report()).Print())
+	assert.NotContains(t, buf.String(), "/logout")
+
+	buf.Reset()
+	assert.NoError(t, availability.NewPrinter(
+		availability.ShowExcluded(true),
+		availability.OutputForPrinting(buf),
+	).For(report()).Print())
+	assert.Contains(t, buf.String(), "[---] https://kamil.samigullin.info/logout -> (skipped: excluded)")
+}
+
+func TestReport_ignoreURLs(t *testing.T) {
+	ignored, err := availability.CompilePatterns([]string{"http://flaky.dev/*"})
+	assert.NoError(t, err)
+	report := func() *availability.Report {
+		crawler := &CrawlerMock{shift: func(to availability.EventBus) {
+			to <- availability.ResponseEvent{StatusCode: http.StatusOK, Location: "http://test.dev/"}
+			to <- availability.WalkEvent{Page: "http://test.dev/", Href: "http://flaky.dev/api"}
+			to <- availability.WalkEvent{Page: "http://test.dev/", Href: "http://unavailable.dev/"}
+			to <- availability.ErrorEvent{StatusCode: http.StatusNotFound, Location: "http://flaky.dev/api"}
+			to <- availability.ErrorEvent{StatusCode: http.StatusServiceUnavailable, Location: "http://unavailable.dev/"}
+			close(to)
+		}}
+		crawler.On("Visit", "http://test.dev/", mock.Anything).Return(nil)
+		return availability.NewReport(
+			availability.CrawlerForSites(crawler),
+			availability.IgnoreURLs(ignored),
+		).For([]string{"http://test.dev/"}).Fill()
+	}
+
+	policy, err := availability.NewFailPolicy(availability.ClientErrorCategory, availability.ServerErrorCategory)
+	assert.NoError(t, err)
+	assert.EqualError(t, policy.Check(report()), "found issues: 1 5xx")
+
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(t, availability.NewPrinter(availability.OutputForPrinting(buf)).For(report()).Print())
+	assert.NotContains(t, buf.String(), "http://flaky.dev/api")
+	assert.Contains(t, buf.String(), "[503] http://unavailable.dev/")
+
+	buf.Reset()
+	assert.NoError(t, availability.NewPrinter(
+		availability.ShowExcluded(true),
+		availability.OutputForPrinting(buf),
+	).For(report()).Print())
+	assert.Contains(t, buf.String(), "[404] http://flaky.dev/api")
+}
@@ -0,0 +1,61 @@
+package availability
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// newSiteMatcher returns a matcher of hosts which belong to the website
+// with the passed entry point by the config.
+func newSiteMatcher(base *url.URL, config CrawlerConfig) *siteMatcher {
+	m := &siteMatcher{host: strings.ToLower(base.Host), allowed: make(map[string]bool, len(config.AllowedHosts))}
+	for _, host := range config.AllowedHosts {
+		m.allowed[strings.ToLower(host)] = true
+	}
+	if config.SameSite {
+		m.domain = registrableDomain(base.Hostname())
+	}
+	return m
+}
+
+// siteMatcher decides whether a link belongs to the website:
+// it has the same host as the entry point, one of the allowed hosts
+// or the same registrable domain if the same site mode is enabled.
+type siteMatcher struct {
+	host    string
+	allowed map[string]bool
+	domain  string
+}
+
+func (m *siteMatcher) contains(u *url.URL) bool {
+	host := strings.ToLower(u.Host)
+	switch {
+	case host == m.host:
+		return true
+	case m.allowed[host], m.allowed[strings.ToLower(u.Hostname())]:
+		return true
+	case m.domain != "":
+		return registrableDomain(u.Hostname()) == m.domain
+	}
+	return false
+}
+
+// registrableDomain returns a part of the host under which it's registered
+// by the public suffix list, e.g. example.com for www.example.com
+// or example.co.uk for docs.example.co.uk. IP addresses are returned as is.
+// It returns an empty string if the host is a public suffix itself,
+// e.g. co.uk or github.io, so it belongs to no site.
+func registrableDomain(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return ""
+	}
+	return domain
+}
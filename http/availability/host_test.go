@@ -0,0 +1,126 @@
+package availability_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.octolab.org/unsafe"
+
+	"github.com/kamilsk/check/http/availability"
+)
+
+func TestCrawlerColly_sameSite(t *testing.T) {
+	type link = struct {
+		Href string
+		Text string
+	}
+	serve := func(links func() []link) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.URL.Path != "/" {
+				rw.WriteHeader(http.StatusOK)
+				return
+			}
+			unsafe.Ignore(tpl.Execute(rw, links()))
+		}))
+	}
+	docs := serve(func() []link { return []link{{Href: "/guide", Text: "guide"}} })
+	defer docs.Close()
+	docsURL, err := url.Parse(docs.URL)
+	assert.NoError(t, err)
+
+	var docsEntry string
+	www := serve(func() []link { return []link{{Href: docsEntry, Text: "docs"}} })
+	defer www.Close()
+	wwwURL, err := url.Parse(www.URL)
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		host, other string
+		config      availability.CrawlerConfig
+		internal    bool
+	}{
+		"external by default": {"www.example.test", "docs.example.test", availability.CrawlerConfig{}, false},
+		"allowed host": {
+			"www.example.test", "docs.example.test",
+			availability.CrawlerConfig{AllowedHosts: []string{"Docs.Example.test"}},
+			true,
+		},
+		"allowed host with port": {
+			"www.example.test", "docs.example.test",
+			availability.CrawlerConfig{AllowedHosts: []string{"docs.example.test:" + docsURL.Port()}},
+			true,
+		},
+		"same site": {"www.example.test", "docs.example.test", availability.CrawlerConfig{SameSite: true}, true},
+		"same site under a ccTLD second level suffix": {
+			"www.example.com.pl", "docs.example.com.pl",
+			availability.CrawlerConfig{SameSite: true},
+			true,
+		},
+		"other site under a ccTLD second level suffix": {
+			"www.example.com.pl", "shop.other.com.pl",
+			availability.CrawlerConfig{SameSite: true},
+			false,
+		},
+		"other site under an academic ccTLD suffix": {
+			"www.example.ac.kr", "www.other.ac.kr",
+			availability.CrawlerConfig{SameSite: true},
+			false,
+		},
+		"other site under a private suffix": {
+			"example.github.io", "other.github.io",
+			availability.CrawlerConfig{SameSite: true},
+			false,
+		},
+		"other bucket under a private suffix": {
+			"example.s3.amazonaws.com", "other.s3.amazonaws.com",
+			availability.CrawlerConfig{SameSite: true},
+			false,
+		},
+		"entry point is a public suffix": {
+			"github.io", "docs.github.io",
+			availability.CrawlerConfig{SameSite: true},
+			false,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			entry := "http://" + test.host + ":" + wwwURL.Port() + "/"
+			docsEntry = "http://" + test.other + ":" + docsURL.Port() + "/"
+			resolve, err := availability.ParseResolve([]string{
+				test.host + ":" + wwwURL.Port() + ":" + wwwURL.Hostname(),
+				test.other + ":" + docsURL.Port() + ":" + docsURL.Hostname(),
+			})
+			assert.NoError(t, err)
+			config := test.config
+			config.Resolve = resolve
+
+			report := availability.NewReport(
+				availability.CrawlerForSites(availability.CrawlerColly(config)),
+			).For([]string{entry}).Fill()
+			for site := range report.Sites() {
+				assert.NoError(t, site.Error)
+				pages := make(map[string]*availability.Page, len(site.Pages))
+				for _, page := range site.Pages {
+					pages[page.Location] = page
+				}
+				assert.Contains(t, pages, entry)
+				assert.Len(t, pages[entry].Links, 1)
+				assert.Equal(t, docsEntry, pages[entry].Links[0].Location)
+				assert.Equal(t, test.internal, pages[entry].Links[0].Internal)
+				if !test.internal {
+					assert.Len(t, pages, 1)
+					continue
+				}
+				assert.Len(t, pages, 2)
+				assert.Contains(t, pages, docsEntry)
+				assert.Len(t, pages[docsEntry].Links, 1)
+				assert.Equal(t, docsEntry+"guide", pages[docsEntry].Links[0].Location)
+				assert.True(t, pages[docsEntry].Links[0].Internal)
+			}
+		})
+	}
+}
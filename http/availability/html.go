@@ -0,0 +1,285 @@
+package availability
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// printHTML streams the report as a self-contained HTML document:
+// each site becomes a section with summary counts by status class
+// and a sortable and filterable table of its distinct links.
+func (p *Printer) printHTML(w io.Writer) error {
+	if err := document.ExecuteTemplate(w, "header", nil); err != nil {
+		return err
+	}
+	for site := range p.report.Sites() {
+		if err := document.ExecuteTemplate(w, "site", p.encodeHTMLSite(p.filter(site))); err != nil {
+			return err
+		}
+	}
+	return document.ExecuteTemplate(w, "footer", nil)
+}
+
+type htmlSite struct {
+	Name          string
+	Error         string
+	Summary       []htmlCount
+	Links         []htmlLink
+	Discrepancies []htmlList
+	Problems      []htmlProblems
+}
+
+type htmlCount struct {
+	Class string
+	Count int
+}
+
+type htmlLink struct {
+	Class    string
+	Status   string
+	Location string
+	Text     string
+	Kind     string
+	Details  string
+	Pages    []htmlPage
+}
+
+type htmlPage struct {
+	Location string
+	Text     string
+}
+
+type htmlProblems struct {
+	Title    string
+	Problems []ProblemEvent
+}
+
+type htmlList struct {
+	Title string
+	Links []htmlLink
+}
+
+func (p *Printer) encodeHTMLSite(site Site) htmlSite {
+	encoded := htmlSite{Name: site.Name}
+	for _, group := range []htmlProblems{
+		{"Insecure links on https pages", problemsOf(site.Problems, InsecureLinkProblem)},
+		{"Mixed content on https pages", problemsOf(site.Problems, MixedContentProblem)},
+		{"Problems", problemsOf(site.Problems, "")},
+	} {
+		if len(group.Problems) > 0 {
+			encoded.Problems = append(encoded.Problems, group)
+		}
+	}
+	if site.Error != nil {
+		encoded.Error = site.Error.Error()
+		return encoded
+	}
+	counts := make(map[string]int, len(statusClasses))
+	for _, ref := range site.References() {
+		if !p.shows(ref.Link) {
+			continue
+		}
+		link := p.encodeHTMLLink(ref.Link)
+		for _, location := range ref.Pages {
+			link.Pages = append(link.Pages, htmlPage{Location: location, Text: p.decoder(location)})
+		}
+		counts[link.Class]++
+		encoded.Links = append(encoded.Links, link)
+	}
+	for _, class := range statusClasses {
+		if count := counts[class]; count > 0 {
+			encoded.Summary = append(encoded.Summary, htmlCount{Class: class, Count: count})
+		}
+	}
+	for _, group := range []struct {
+		title string
+		links []Link
+	}{
+		{"Orphan pages", site.Orphans},
+		{"Pages missing from the sitemap", site.Unlisted},
+		{"Fixed links since the baseline", site.Fixed},
+	} {
+		if len(group.links) == 0 {
+			continue
+		}
+		list := htmlList{Title: group.title}
+		for _, link := range group.links {
+			list.Links = append(list.Links, p.encodeHTMLLink(link))
+		}
+		encoded.Discrepancies = append(encoded.Discrepancies, list)
+	}
+	return encoded
+}
+
+func (p *Printer) encodeHTMLLink(link Link) htmlLink {
+	encoded := htmlLink{
+		Class:    link.StatusClass(),
+		Status:   strconv.Itoa(link.StatusCode),
+		Location: link.Location,
+		Text:     p.decoder(link.Location),
+		Kind:     link.Kind,
+	}
+	if encoded.Kind == "" {
+		encoded.Kind = AnchorKind
+	}
+	details := make([]string, 0, 4)
+	if link.Skipped != "" {
+		encoded.Status = "---"
+		details = append(details, "skipped: "+link.Skipped)
+	}
+	if link.BrokenAnchor != "" {
+		details = append(details, "broken anchor: #"+link.BrokenAnchor)
+	}
+	if link.Error != nil {
+		details = append(details, link.Error.Error())
+	}
+	for _, hop := range link.Hops {
+		details = append(details, fmt.Sprintf("→ [%d] %s", hop.StatusCode, p.decoder(hop.Location)))
+	}
+	if len(link.Hops) == 0 && link.Redirect != "" {
+		details = append(details, "→ "+p.decoder(link.Redirect))
+	}
+	if link.RedirectLoop {
+		details = append(details, "redirect loop")
+	}
+	if link.RedirectTruncated {
+		details = append(details, "redirect chain truncated")
+	}
+	if link.Downgrade() {
+		details = append(details, "downgrade to http")
+	}
+	encoded.Details = strings.Join(details, "; ")
+	return encoded
+}
+
+var document = template.Must(template.New("html").Parse(`
+{{- define "header" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Availability report</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292e; }
+h2 { border-bottom: 1px solid #e1e4e8; padding-bottom: .3em; }
+.summary { display: flex; flex-wrap: wrap; gap: .5em; padding: 0; list-style: none; }
+.summary li { padding: .3em .8em; border-radius: 1em; background: #f1f8ff; }
+.filters { margin: 1em 0; }
+.filters input { width: 30em; max-width: 100%; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #e1e4e8; padding: .4em .6em; text-align: left; vertical-align: top; word-break: break-all; }
+th { background: #f6f8fa; cursor: pointer; user-select: none; }
+th[data-order="asc"]::after { content: " ▲"; }
+th[data-order="desc"]::after { content: " ▼"; }
+.class-2xx { background: #e6ffed; }
+.class-3xx { background: #fff5b1; }
+.class-4xx, .class-5xx, .class-error, .class-timeout, .class-anchor { background: #ffeef0; }
+.class-skipped { color: #6a737d; }
+.error { color: #cb2431; }
+</style>
+</head>
+<body>
+<h1>Availability report</h1>
+{{- end -}}
+
+{{- define "site" }}
+<section class="site">
+<h2>{{ .Name }}</h2>
+{{- with .Error }}
+<p class="error">{{ . }}</p>
+{{- else }}
+<ul class="summary">
+{{- range .Summary }}
+<li class="class-{{ .Class }}">{{ .Class }}: {{ .Count }}</li>
+{{- end }}
+</ul>
+<div class="filters">
+<input type="search" placeholder="Filter by URL or page">
+<select>
+<option value="">all</option>
+{{- range .Summary }}
+<option value="{{ .Class }}">{{ .Class }}</option>
+{{- end }}
+</select>
+</div>
+<table>
+<thead>
+<tr><th data-type="number">Status</th><th>URL</th><th>Kind</th><th>Details</th><th data-type="number">Appears on pages</th></tr>
+</thead>
+<tbody>
+{{- range .Links }}
+<tr class="class-{{ .Class }}" data-class="{{ .Class }}">
+<td>{{ .Status }}</td>
+<td><a href="{{ .Location }}">{{ .Text }}</a></td>
+<td>{{ .Kind }}</td>
+<td>{{ .Details }}</td>
+<td data-value="{{ len .Pages }}">{{ if .Pages }}<details><summary>{{ len .Pages }}</summary><ul>
+{{- range .Pages }}<li><a href="{{ .Location }}">{{ .Text }}</a></li>{{ end -}}
+</ul></details>{{ else }}0{{ end }}</td>
+</tr>
+{{- end }}
+</tbody>
+</table>
+{{- range .Discrepancies }}
+<h3>{{ .Title }}</h3>
+<ul>
+{{- range .Links }}
+<li class="class-{{ .Class }}">[{{ .Status }}] <a href="{{ .Location }}">{{ .Text }}</a></li>
+{{- end }}
+</ul>
+{{- end }}
+{{- end }}
+{{- range .Problems }}
+<h3>{{ .Title }}</h3>
+<ul>
+{{- range .Problems }}
+<li class="error">{{ .Message }} <code>{{ printf "%+v" .Context }}</code></li>
+{{- end }}
+</ul>
+{{- end }}
+</section>
+{{- end -}}
+
+{{- define "footer" }}
+<script>
+document.querySelectorAll("section.site").forEach(function (site) {
+  var table = site.querySelector("table");
+  if (!table) {
+    return;
+  }
+  var body = table.tBodies[0], search = site.querySelector(".filters input"), select = site.querySelector(".filters select");
+  var value = function (row, index, numeric) {
+    var cell = row.cells[index], text = cell.getAttribute("data-value") || cell.textContent;
+    return numeric ? parseFloat(text) || 0 : text.toLowerCase();
+  };
+  table.querySelectorAll("th").forEach(function (th, index) {
+    th.addEventListener("click", function () {
+      var numeric = th.getAttribute("data-type") === "number", order = th.getAttribute("data-order") === "asc" ? "desc" : "asc";
+      table.querySelectorAll("th").forEach(function (other) { other.removeAttribute("data-order"); });
+      th.setAttribute("data-order", order);
+      Array.prototype.slice.call(body.rows).sort(function (a, b) {
+        var x = value(a, index, numeric), y = value(b, index, numeric);
+        return (x < y ? -1 : x > y ? 1 : 0) * (order === "asc" ? 1 : -1);
+      }).forEach(function (row) { body.appendChild(row); });
+    });
+  });
+  var filter = function () {
+    var text = search.value.toLowerCase(), status = select.value;
+    Array.prototype.forEach.call(body.rows, function (row) {
+      var visible = (!status || row.getAttribute("data-class") === status) &&
+        (!text || row.textContent.toLowerCase().indexOf(text) !== -1);
+      row.style.display = visible ? "" : "none";
+    });
+  };
+  search.addEventListener("input", filter);
+  select.addEventListener("change", filter);
+});
+</script>
+</body>
+</html>
+{{ end -}}
+`))
@@ -0,0 +1,67 @@
+package availability_test
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kamilsk/check/errors"
+	"github.com/kamilsk/check/http/availability"
+)
+
+func TestPrinter_printHTML(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+
+	home := &availability.Page{
+		Link: &availability.Link{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/"},
+	}
+	en := &availability.Page{
+		Link: &availability.Link{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/en/"},
+	}
+	broken := availability.Link{StatusCode: http.StatusNotFound, Location: "https://github.com/kamilsk/404"}
+	unreachable := availability.Link{Location: "https://unreachable.dev/", Error: errors.Simple("no such host")}
+	redirect := availability.Link{StatusCode: http.StatusFound, Location: "http://howilive.ru/en/",
+		Redirect: "https://howilive.ru/en/", Error: errors.Simple("Found")}
+	script := availability.Link{Location: "javascript:alert('<xss>')", Skipped: availability.ExternalSkipReason}
+	home.Links = []availability.Link{broken, redirect, script, {Internal: true, StatusCode: http.StatusOK,
+		Location: "https://kamil.samigullin.info/en/"}}
+	en.Links = []availability.Link{broken, unreachable}
+
+	m := &PrinterMock{}
+	data := make(chan availability.Site, 2)
+	data <- availability.Site{
+		Name:     "kamil.samigullin.info",
+		Pages:    []*availability.Page{home, en},
+		Problems: []availability.ProblemEvent{{Message: "bad url", Context: ":bad"}},
+	}
+	data <- *availability.NewSite(":bad")
+	close(data)
+	var pipe <-chan availability.Site = data
+	m.On("Sites").Return(pipe)
+
+	printer := availability.NewPrinter(
+		availability.FormatOutput(availability.HTMLFormat),
+		availability.OutputForPrinting(buf),
+	)
+	assert.NoError(t, printer.For(m).Print())
+
+	html := buf.String()
+	assert.Contains(t, html, "<!DOCTYPE html>")
+	assert.Contains(t, html, "</html>")
+	assert.Contains(t, html, `<h2>kamil.samigullin.info</h2>`)
+	assert.Contains(t, html, `<li class="class-2xx">2xx: 2</li>`)
+	assert.Contains(t, html, `<li class="class-3xx">3xx: 1</li>`)
+	assert.Contains(t, html, `<li class="class-4xx">4xx: 1</li>`)
+	assert.Contains(t, html, `<li class="class-error">error: 1</li>`)
+	assert.Contains(t, html, `<li class="class-skipped">skipped: 1</li>`)
+	assert.Contains(t, html, `<td data-value="2"><details><summary>2</summary><ul>`+
+		`<li><a href="https://kamil.samigullin.info/">https://kamil.samigullin.info/</a></li>`+
+		`<li><a href="https://kamil.samigullin.info/en/">https://kamil.samigullin.info/en/</a></li>`+
+		`</ul></details></td>`)
+	assert.Contains(t, html, "Found; → https://howilive.ru/en/")
+	assert.Contains(t, html, `<a href="#ZgotmplZ">javascript:alert(&#39;&lt;xss&gt;&#39;)</a>`)
+	assert.Contains(t, html, "bad url")
+	assert.Contains(t, html, `<p class="error">parse rawURL &#34;:bad&#34; for report`)
+}
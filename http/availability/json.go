@@ -0,0 +1,233 @@
+package availability
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/kamilsk/check/errors"
+)
+
+// printJSON streams the report as a JSON array of sites, one site per line,
+// so a site is encoded as soon as it is received from the report provider.
+func (p *Printer) printJSON(w io.Writer) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	separator := "\n"
+	for site := range p.report.Sites() {
+		blob, err := json.Marshal(encodeSite(p.filter(site)))
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(w, separator); err != nil {
+			return err
+		}
+		if _, err = w.Write(blob); err != nil {
+			return err
+		}
+		separator = ",\n"
+	}
+	_, err := io.WriteString(w, "\n]\n")
+	return err
+}
+
+type jsonSite struct {
+	Name     string        `json:"name"`
+	Error    string        `json:"error,omitempty"`
+	Pages    []jsonPage    `json:"pages"`
+	Problems []jsonProblem `json:"problems"`
+	Orphans  []jsonLink    `json:"orphans,omitempty"`
+	Unlisted []jsonLink    `json:"unlisted,omitempty"`
+	Fixed    []jsonLink    `json:"fixed,omitempty"`
+}
+
+type jsonPage struct {
+	jsonLink
+	Links []jsonLink `json:"links"`
+}
+
+type jsonLink struct {
+	StatusCode   int       `json:"status_code"`
+	Location     string    `json:"location"`
+	Redirect     string    `json:"redirect,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	Timeout      bool      `json:"timeout,omitempty"`
+	Internal     bool      `json:"internal"`
+	Page         string    `json:"page,omitempty"`
+	Attempts     int       `json:"attempts,omitempty"`
+	Skipped      string    `json:"skipped,omitempty"`
+	Kind         string    `json:"kind,omitempty"`
+	BrokenAnchor string    `json:"broken_anchor,omitempty"`
+	Hops         []jsonHop `json:"hops,omitempty"`
+	RedirectLoop bool      `json:"redirect_loop,omitempty"`
+	Downgrade    bool      `json:"downgrade,omitempty"`
+
+	RedirectTruncated bool `json:"redirect_truncated,omitempty"`
+}
+
+type jsonHop struct {
+	StatusCode int    `json:"status_code"`
+	Location   string `json:"location"`
+	Error      string `json:"error,omitempty"`
+}
+
+type jsonProblem struct {
+	Category string      `json:"category,omitempty"`
+	Message  string      `json:"message"`
+	Context  interface{} `json:"context,omitempty"`
+}
+
+func encodeSite(site Site) jsonSite {
+	encoded := jsonSite{
+		Name:     site.Name,
+		Error:    errorString(site.Error),
+		Pages:    make([]jsonPage, 0, len(site.Pages)),
+		Problems: make([]jsonProblem, 0, len(site.Problems)),
+	}
+	sort.Sort(pagesByLocation(site.Pages))
+	for _, page := range site.Pages {
+		sort.Sort(linksByStatusCode(page.Links))
+		links := make([]jsonLink, 0, len(page.Links))
+		for _, link := range page.Links {
+			links = append(links, encodeLink(link))
+		}
+		var self jsonLink
+		if page.Link != nil {
+			self = encodeLink(*page.Link)
+		}
+		encoded.Pages = append(encoded.Pages, jsonPage{jsonLink: self, Links: links})
+	}
+	for _, link := range site.Orphans {
+		encoded.Orphans = append(encoded.Orphans, encodeLink(link))
+	}
+	for _, link := range site.Unlisted {
+		encoded.Unlisted = append(encoded.Unlisted, encodeLink(link))
+	}
+	for _, link := range site.Fixed {
+		encoded.Fixed = append(encoded.Fixed, encodeLink(link))
+	}
+	for _, problem := range site.Problems {
+		encoded.Problems = append(encoded.Problems, jsonProblem{
+			Category: problem.Category,
+			Message:  problem.Message,
+			Context:  problem.Context,
+		})
+	}
+	return encoded
+}
+
+// LoadSites reads websites from a report in the JSON format.
+func LoadSites(r io.Reader) ([]Site, error) {
+	var encoded []jsonSite
+	if err := json.NewDecoder(r).Decode(&encoded); err != nil {
+		return nil, errors.Wrapf(err, "decode report")
+	}
+	sites := make([]Site, 0, len(encoded))
+	for _, site := range encoded {
+		sites = append(sites, decodeSite(site))
+	}
+	return sites, nil
+}
+
+func decodeSite(encoded jsonSite) Site {
+	site := Site{
+		Name:  encoded.Name,
+		Pages: make([]*Page, 0, len(encoded.Pages)),
+	}
+	if encoded.Error != "" {
+		site.Error = errors.Simple(encoded.Error)
+	}
+	for _, encodedPage := range encoded.Pages {
+		self := decodeLink(encodedPage.jsonLink)
+		page := &Page{Link: &self, Links: make([]Link, 0, len(encodedPage.Links))}
+		for _, encodedLink := range encodedPage.Links {
+			link := decodeLink(encodedLink)
+			link.Page = page
+			page.Links = append(page.Links, link)
+		}
+		site.Pages = append(site.Pages, page)
+	}
+	for _, link := range encoded.Orphans {
+		site.Orphans = append(site.Orphans, decodeLink(link))
+	}
+	for _, link := range encoded.Unlisted {
+		site.Unlisted = append(site.Unlisted, decodeLink(link))
+	}
+	for _, link := range encoded.Fixed {
+		site.Fixed = append(site.Fixed, decodeLink(link))
+	}
+	for _, problem := range encoded.Problems {
+		site.Problems = append(site.Problems, ProblemEvent{
+			Category: problem.Category,
+			Message:  problem.Message,
+			Context:  problem.Context,
+		})
+	}
+	return site
+}
+
+func encodeLink(link Link) jsonLink {
+	encoded := jsonLink{
+		StatusCode:   link.StatusCode,
+		Location:     link.Location,
+		Redirect:     link.Redirect,
+		Error:        errorString(link.Error),
+		Timeout:      link.Timeout,
+		Internal:     link.Internal,
+		Attempts:     link.Attempts,
+		Skipped:      link.Skipped,
+		Kind:         link.Kind,
+		BrokenAnchor: link.BrokenAnchor,
+		RedirectLoop: link.RedirectLoop,
+		Downgrade:    link.Downgrade(),
+
+		RedirectTruncated: link.RedirectTruncated,
+	}
+	for _, hop := range link.Hops {
+		encoded.Hops = append(encoded.Hops, jsonHop{
+			StatusCode: hop.StatusCode,
+			Location:   hop.Location,
+			Error:      errorString(hop.Error),
+		})
+	}
+	if link.Page != nil && link.Page.Link != nil {
+		encoded.Page = link.Page.Location
+	}
+	return encoded
+}
+
+func decodeLink(encoded jsonLink) Link {
+	link := Link{
+		Internal:     encoded.Internal,
+		StatusCode:   encoded.StatusCode,
+		Location:     encoded.Location,
+		Redirect:     encoded.Redirect,
+		Timeout:      encoded.Timeout,
+		Attempts:     encoded.Attempts,
+		Skipped:      encoded.Skipped,
+		Kind:         encoded.Kind,
+		BrokenAnchor: encoded.BrokenAnchor,
+		RedirectLoop: encoded.RedirectLoop,
+
+		RedirectTruncated: encoded.RedirectTruncated,
+	}
+	if encoded.Error != "" {
+		link.Error = errors.Simple(encoded.Error)
+	}
+	for _, hop := range encoded.Hops {
+		decoded := Hop{StatusCode: hop.StatusCode, Location: hop.Location}
+		if hop.Error != "" {
+			decoded.Error = errors.Simple(hop.Error)
+		}
+		link.Hops = append(link.Hops, decoded)
+	}
+	return link
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
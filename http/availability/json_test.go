@@ -0,0 +1,137 @@
+package availability_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.octolab.org/unsafe"
+
+	"github.com/kamilsk/check/errors"
+	"github.com/kamilsk/check/http/availability"
+)
+
+func TestPrinter_printJSON(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+
+	page := &availability.Page{
+		Link: &availability.Link{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/"},
+	}
+	page.Links = []availability.Link{
+		{Page: page, StatusCode: http.StatusNotFound, Location: "https://github.com/kamilsk/404",
+			Error: errors.Simple("Not Found")},
+		{Page: page, StatusCode: http.StatusFound,
+			Location: "http://howilive.ru/en/", Redirect: "https://howilive.ru/en/"},
+		{Page: page, Internal: true, StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/en/"},
+	}
+
+	m := &PrinterMock{}
+	data := make(chan availability.Site, 2)
+	data <- availability.Site{
+		Name:  "kamil.samigullin.info",
+		Pages: []*availability.Page{page},
+		Problems: []availability.ProblemEvent{
+			{Message: "bad url", Context: ":bad"},
+			{Category: availability.InsecureLinkProblem, Message: "insecure link", Context: "http://howilive.ru/en/"},
+		},
+	}
+	data <- *availability.NewSite(":bad")
+	close(data)
+	var pipe <-chan availability.Site = data
+	m.On("Sites").Return(pipe)
+
+	printer := availability.NewPrinter(
+		availability.FormatOutput(availability.JSONFormat),
+		availability.OutputForPrinting(buf),
+	)
+	assert.NoError(t, printer.For(m).Print())
+
+	var obtained []struct {
+		Name  string `json:"name"`
+		Error string `json:"error"`
+		Pages []struct {
+			StatusCode int    `json:"status_code"`
+			Location   string `json:"location"`
+			Links      []struct {
+				StatusCode int    `json:"status_code"`
+				Location   string `json:"location"`
+				Redirect   string `json:"redirect"`
+				Error      string `json:"error"`
+				Internal   bool   `json:"internal"`
+				Page       string `json:"page"`
+			} `json:"links"`
+		} `json:"pages"`
+		Problems []struct {
+			Category string `json:"category"`
+			Message  string `json:"message"`
+			Context  string `json:"context"`
+		} `json:"problems"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &obtained))
+	assert.Len(t, obtained, 2)
+
+	site := obtained[0]
+	assert.Equal(t, "kamil.samigullin.info", site.Name)
+	assert.Empty(t, site.Error)
+	assert.Len(t, site.Pages, 1)
+	assert.Equal(t, "https://kamil.samigullin.info/", site.Pages[0].Location)
+	assert.Len(t, site.Pages[0].Links, 3)
+	assert.Equal(t, http.StatusOK, site.Pages[0].Links[0].StatusCode)
+	assert.True(t, site.Pages[0].Links[0].Internal)
+	assert.Equal(t, "https://howilive.ru/en/", site.Pages[0].Links[1].Redirect)
+	assert.Equal(t, "Not Found", site.Pages[0].Links[2].Error)
+	assert.Equal(t, "https://kamil.samigullin.info/", site.Pages[0].Links[2].Page)
+	assert.Len(t, site.Problems, 2)
+	assert.Empty(t, site.Problems[0].Category)
+	assert.Equal(t, ":bad", site.Problems[0].Context)
+	assert.Equal(t, availability.InsecureLinkProblem, site.Problems[1].Category)
+
+	sites, err := availability.LoadSites(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, availability.InsecureLinkProblem, sites[0].Problems[1].Category)
+
+	assert.Equal(t, ":bad", obtained[1].Name)
+	assert.Contains(t, obtained[1].Error, `parse rawURL ":bad" for report`)
+
+	buf.Reset()
+	printer = availability.NewPrinter(availability.FormatOutput("yaml"), availability.OutputForPrinting(buf))
+	assert.Error(t, printer.For(m).Print())
+}
+
+func TestPrinter_printJSON_internalPages(t *testing.T) {
+	type link = struct {
+		Href string
+		Text string
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		links := []link{{Href: "/", Text: "home"}, {Href: "/about", Text: "about"}}
+		unsafe.Ignore(tpl.Execute(rw, links))
+	}))
+	defer server.Close()
+
+	buf := bytes.NewBuffer(nil)
+	report := availability.NewReport(availability.CrawlerForSites(availability.CrawlerColly(
+		availability.CrawlerConfig{},
+	))).For([]string{server.URL + "/"}).Fill()
+	printer := availability.NewPrinter(
+		availability.FormatOutput(availability.JSONFormat),
+		availability.OutputForPrinting(buf),
+	)
+	assert.NoError(t, printer.For(report).Print())
+
+	var obtained []struct {
+		Pages []struct {
+			Location string `json:"location"`
+			Internal bool   `json:"internal"`
+		} `json:"pages"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &obtained))
+	assert.Len(t, obtained, 1)
+	assert.Len(t, obtained[0].Pages, 2)
+	for _, page := range obtained[0].Pages {
+		assert.True(t, page.Internal, page.Location)
+	}
+}
@@ -0,0 +1,116 @@
+package availability
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// printJUnit streams the report as a JUnit XML document:
+// each site becomes a test suite and each distinct link becomes a test case.
+func (p *Printer) printJUnit(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header+"<testsuites>\n"); err != nil {
+		return err
+	}
+	for site := range p.report.Sites() {
+		blob, err := xml.MarshalIndent(encodeSuite(p.filter(site), p.shows), "  ", "  ")
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write(blob); err != nil {
+			return err
+		}
+		if _, err = io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "</testsuites>\n")
+	return err
+}
+
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+func encodeSuite(site Site, shows func(Link) bool) junitSuite {
+	suite := junitSuite{Name: site.Name}
+	if site.Error != nil {
+		suite.Cases = append(suite.Cases, junitCase{
+			Name:      site.Name,
+			ClassName: site.Name,
+			Error:     &junitMessage{Message: site.Error.Error(), Type: "site"},
+		})
+	}
+
+	for _, ref := range site.References() {
+		if !shows(ref.Link) {
+			continue
+		}
+		tc := junitCase{Name: ref.Location, ClassName: site.Name}
+		if ref.Skipped != "" {
+			tc.Skipped = &junitMessage{Message: ref.Skipped}
+		}
+		if isBroken(ref.Link) {
+			message := fmt.Sprintf("[%d] %s", ref.StatusCode, ref.Location)
+			if ref.Error != nil {
+				message += fmt.Sprintf(" (%s)", ref.Error)
+			}
+			if ref.BrokenAnchor != "" {
+				message += fmt.Sprintf(" (broken anchor: #%s)", ref.BrokenAnchor)
+			}
+			tc.Failure = &junitMessage{
+				Message: message,
+				Type:    "link",
+				Text:    "found on pages:\n" + strings.Join(ref.Pages, "\n"),
+			}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	for _, problem := range site.Problems {
+		kind := problem.Category
+		if kind == "" {
+			kind = "problem"
+		}
+		suite.Cases = append(suite.Cases, junitCase{
+			Name:      problem.Message,
+			ClassName: site.Name,
+			Error: &junitMessage{
+				Message: problem.Message,
+				Type:    kind,
+				Text:    fmt.Sprintf("%+v", problem.Context),
+			},
+		})
+	}
+
+	suite.Tests = len(suite.Cases)
+	for _, tc := range suite.Cases {
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+		if tc.Error != nil {
+			suite.Errors++
+		}
+	}
+	return suite
+}
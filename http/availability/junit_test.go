@@ -0,0 +1,94 @@
+package availability_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kamilsk/check/errors"
+	"github.com/kamilsk/check/http/availability"
+)
+
+func TestPrinter_printJUnit(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+
+	home := &availability.Page{
+		Link: &availability.Link{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/"},
+	}
+	en := &availability.Page{
+		Link: &availability.Link{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/en/"},
+	}
+	broken := availability.Link{StatusCode: http.StatusNotFound, Location: "https://github.com/kamilsk/404"}
+	unreachable := availability.Link{Location: "https://unreachable.dev/", Error: errors.Simple("no such host")}
+	redirect := availability.Link{StatusCode: http.StatusFound, Location: "http://howilive.ru/en/",
+		Redirect: "https://howilive.ru/en/", Error: errors.Simple("Found")}
+	home.Links = []availability.Link{broken, redirect, {Internal: true, StatusCode: http.StatusOK,
+		Location: "https://kamil.samigullin.info/en/"}}
+	en.Links = []availability.Link{broken, unreachable}
+
+	m := &PrinterMock{}
+	data := make(chan availability.Site, 2)
+	data <- availability.Site{
+		Name:     "kamil.samigullin.info",
+		Pages:    []*availability.Page{home, en},
+		Problems: []availability.ProblemEvent{{Message: "bad url", Context: ":bad"}},
+	}
+	data <- *availability.NewSite(":bad")
+	close(data)
+	var pipe <-chan availability.Site = data
+	m.On("Sites").Return(pipe)
+
+	printer := availability.NewPrinter(
+		availability.FormatOutput(availability.JUnitFormat),
+		availability.OutputForPrinting(buf),
+	)
+	assert.NoError(t, printer.For(m).Print())
+
+	type message struct {
+		Message string `xml:"message,attr"`
+		Text    string `xml:",chardata"`
+	}
+	var obtained struct {
+		Suites []struct {
+			Name     string `xml:"name,attr"`
+			Tests    int    `xml:"tests,attr"`
+			Failures int    `xml:"failures,attr"`
+			Errors   int    `xml:"errors,attr"`
+			Cases    []struct {
+				Name    string   `xml:"name,attr"`
+				Failure *message `xml:"failure"`
+				Error   *message `xml:"error"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &obtained))
+	assert.Len(t, obtained.Suites, 2)
+
+	suite := obtained.Suites[0]
+	assert.Equal(t, "kamil.samigullin.info", suite.Name)
+	assert.Equal(t, 6, suite.Tests)
+	assert.Equal(t, 2, suite.Failures)
+	assert.Equal(t, 1, suite.Errors)
+	for _, tc := range suite.Cases {
+		switch tc.Name {
+		case broken.Location:
+			assert.NotNil(t, tc.Failure)
+			assert.Contains(t, tc.Failure.Text, "https://kamil.samigullin.info/\nhttps://kamil.samigullin.info/en/")
+		case unreachable.Location:
+			assert.NotNil(t, tc.Failure)
+			assert.Contains(t, tc.Failure.Message, "no such host")
+		case "bad url":
+			assert.NotNil(t, tc.Error)
+		default:
+			assert.Nil(t, tc.Failure)
+			assert.Nil(t, tc.Error)
+		}
+	}
+
+	suite = obtained.Suites[1]
+	assert.Equal(t, ":bad", suite.Name)
+	assert.Equal(t, 1, suite.Errors)
+}
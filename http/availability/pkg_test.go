@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/stretchr/testify/mock"
 	"go.octolab.org/unsafe"
@@ -181,6 +182,22 @@ func (c *chain) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	c.Handler.ServeHTTP(rw, req)
 }
 
+// collect visits the website by the crawler and returns all sent events.
+func collect(crawler availability.Crawler, entry string) ([]interface{}, error) {
+	wg, bus := &sync.WaitGroup{}, availability.NewReadableEventBus(8)
+	events := make([]interface{}, 0, 8)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for event := range bus {
+			events = append(events, event)
+		}
+	}()
+	err := crawler.Visit(entry, bus)
+	wg.Wait()
+	return events, err
+}
+
 type CrawlerMock struct {
 	mock.Mock
 	shift func(availability.EventBus)
@@ -0,0 +1,189 @@
+package availability
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kamilsk/check/errors"
+)
+
+// Supported categories of found issues.
+const (
+	RedirectCategory    = "redirect"
+	ClientErrorCategory = "4xx"
+	ServerErrorCategory = "5xx"
+	ErrorCategory       = "error"
+	TimeoutCategory     = "timeout"
+	AnchorCategory      = "anchor"
+	ProblemCategory     = "problem"
+)
+
+// Supported classes of links' statuses in addition to categories of issues.
+const (
+	SuccessClass  = "2xx"
+	RedirectClass = "3xx"
+	SkippedClass  = "skipped"
+)
+
+// statusClasses contains classes of links' statuses in the order of their output.
+var statusClasses = []string{
+	SuccessClass,
+	RedirectClass,
+	ClientErrorCategory,
+	ServerErrorCategory,
+	ErrorCategory,
+	TimeoutCategory,
+	AnchorCategory,
+	SkippedClass,
+}
+
+// ValidateClasses checks that all passed classes of links' statuses are supported.
+// The redirect category is accepted as an alias of the 3xx class.
+func ValidateClasses(classes []string) error {
+	for _, class := range classes {
+		if !isStatusClass(classOf(class)) {
+			return errors.Errorf("unsupported status class %q", class)
+		}
+	}
+	return nil
+}
+
+// severity contains categories and their exit codes, from the most severe one.
+var severity = []struct {
+	name string
+	code int
+}{
+	{ErrorCategory, 6},
+	{TimeoutCategory, 9},
+	{ServerErrorCategory, 5},
+	{ClientErrorCategory, 4},
+	{AnchorCategory, 8},
+	{ProblemCategory, 7},
+	{RedirectCategory, 3},
+}
+
+// NewFailPolicy returns a policy that fails a check
+// if a report contains issues of the passed categories.
+func NewFailPolicy(categories ...string) (FailPolicy, error) {
+	policy := make(FailPolicy, len(categories))
+	for _, category := range categories {
+		category = categoryOf(strings.TrimSpace(category))
+		if !isCategory(category) {
+			return nil, errors.Errorf("unsupported category %q", category)
+		}
+		policy[category] = true
+	}
+	return policy, nil
+}
+
+// FailPolicy defines categories of issues that fail a check.
+type FailPolicy map[string]bool
+
+// Check inspects the filled report and returns *PolicyViolation
+// if it contains issues of the categories specified by the policy.
+// Issues known by the baseline and of ignored links are skipped.
+// A failed crawling of a website is returned as is,
+// because its issues can't be inspected.
+func (policy FailPolicy) Check(report *Report) error {
+	if len(policy) == 0 || report == nil {
+		return nil
+	}
+	for _, site := range report.sites {
+		if site.Error != nil {
+			return errors.WithMessage(site.Error, fmt.Sprintf("crawl website %q", site.Name))
+		}
+	}
+	violation := &PolicyViolation{Counts: make(map[string]int)}
+	for _, site := range report.sites {
+		if len(site.Problems) > 0 && policy[ProblemCategory] {
+			violation.Counts[ProblemCategory] += len(site.Problems)
+		}
+		for _, ref := range site.References() {
+			if ref.Known || ref.Ignored {
+				continue
+			}
+			if category := ref.Category(); category != "" && policy[category] {
+				violation.Counts[category]++
+			}
+		}
+	}
+	if len(violation.Counts) == 0 {
+		return nil
+	}
+	return violation
+}
+
+// PolicyViolation is returned by FailPolicy
+// and contains counts of found issues per category.
+type PolicyViolation struct {
+	Counts map[string]int
+}
+
+// Error returns a description of found issues.
+func (err *PolicyViolation) Error() string {
+	found := make([]string, 0, len(err.Counts))
+	for _, category := range severity {
+		if count := err.Counts[category.name]; count > 0 {
+			found = append(found, fmt.Sprintf("%d %s", count, category.name))
+		}
+	}
+	return "found issues: " + strings.Join(found, ", ")
+}
+
+// ExitCode returns an exit code related to the most severe category of found issues.
+func (err *PolicyViolation) ExitCode() int {
+	for _, category := range severity {
+		if err.Counts[category.name] > 0 {
+			return category.code
+		}
+	}
+	return 0
+}
+
+// rank returns a position of the link's category in the order of severity,
+// links without issues are ranked last.
+func rank(link Link) int {
+	category := link.Category()
+	for i := range severity {
+		if severity[i].name == category {
+			return i
+		}
+	}
+	return len(severity)
+}
+
+// classOf returns a class of links' statuses by its name or an alias,
+// so filters accept the same names as fail policies.
+func classOf(name string) string {
+	if name == RedirectCategory {
+		return RedirectClass
+	}
+	return name
+}
+
+// categoryOf returns a category of issues by its name or an alias,
+// so fail policies accept the same names as filters.
+func categoryOf(name string) string {
+	if name == RedirectClass {
+		return RedirectCategory
+	}
+	return name
+}
+
+func isStatusClass(name string) bool {
+	for _, class := range statusClasses {
+		if class == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isCategory(name string) bool {
+	for _, category := range severity {
+		if category.name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package availability_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/kamilsk/check/errors"
+	"github.com/kamilsk/check/http/availability"
+)
+
+func TestFailPolicy(t *testing.T) {
+	report := func() *availability.Report {
+		crawler := &CrawlerMock{shift: func(to availability.EventBus) {
+			to <- availability.ResponseEvent{StatusCode: http.StatusOK, Location: "http://test.dev/"}
+			to <- availability.WalkEvent{Page: "http://test.dev/", Href: "http://redirect.dev/"}
+			to <- availability.WalkEvent{Page: "http://test.dev/", Href: "http://noaccess.dev/"}
+			to <- availability.WalkEvent{Page: "http://test.dev/", Href: "http://unavailable.dev/"}
+			to <- availability.WalkEvent{Page: "http://test.dev/", Href: "http://unreachable.dev/"}
+			to <- availability.ProblemEvent{Message: "bad url", Context: ":bad"}
+			to <- availability.ErrorEvent{StatusCode: http.StatusFound,
+				Location: "http://redirect.dev/", Redirect: "https://redirect.dev/"}
+			to <- availability.ErrorEvent{StatusCode: http.StatusForbidden, Location: "http://noaccess.dev/"}
+			to <- availability.ErrorEvent{StatusCode: http.StatusServiceUnavailable, Location: "http://unavailable.dev/"}
+			to <- availability.ErrorEvent{Location: "http://unreachable.dev/", Error: errors.Simple("no such host")}
+			close(to)
+		}}
+		crawler.On("Visit", "http://test.dev/", mock.Anything).Return(nil)
+		return availability.NewReport(availability.CrawlerForSites(crawler)).For([]string{"http://test.dev/"}).Fill()
+	}
+
+	tests := []struct {
+		name       string
+		categories []string
+		expected   string
+		code       int
+	}{
+		{"empty policy", nil, "", 0},
+		{"redirect", []string{availability.RedirectCategory}, "found issues: 1 redirect", 3},
+		{"redirect class", []string{availability.RedirectClass}, "found issues: 1 redirect", 3},
+		{"client error", []string{availability.ClientErrorCategory}, "found issues: 1 4xx", 4},
+		{"server error", []string{availability.ServerErrorCategory}, "found issues: 1 5xx", 5},
+		{"network error", []string{availability.ErrorCategory}, "found issues: 1 error", 6},
+		{"problem", []string{availability.ProblemCategory}, "found issues: 1 problem", 7},
+		{
+			"the most severe",
+			[]string{"redirect", "4xx", "5xx", "problem"},
+			"found issues: 1 5xx, 1 4xx, 1 problem, 1 redirect",
+			5,
+		},
+	}
+	for _, test := range tests {
+		tc := test
+		t.Run(test.name, func(t *testing.T) {
+			policy, err := availability.NewFailPolicy(tc.categories...)
+			assert.NoError(t, err)
+			err = policy.Check(report())
+			if tc.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.expected)
+			var violation *availability.PolicyViolation
+			assert.True(t, errors.As(err, &violation))
+			assert.Equal(t, tc.code, violation.ExitCode())
+		})
+	}
+
+	t.Run("failed crawling", func(t *testing.T) {
+		crawler := &CrawlerMock{shift: func(to availability.EventBus) { close(to) }}
+		crawler.On("Visit", "http://test.dev/", mock.Anything).Return(errors.Simple("crawler crashed"))
+		report := availability.NewReport(availability.CrawlerForSites(crawler)).For([]string{"http://test.dev/"}).Fill()
+		policy, err := availability.NewFailPolicy(availability.ErrorCategory)
+		assert.NoError(t, err)
+		err = policy.Check(report)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "crawler crashed")
+		var violation *availability.PolicyViolation
+		assert.False(t, errors.As(err, &violation))
+	})
+
+	t.Run("unsupported category", func(t *testing.T) {
+		_, err := availability.NewFailPolicy(availability.SuccessClass)
+		assert.Error(t, err)
+	})
+}
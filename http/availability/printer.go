@@ -16,6 +16,20 @@ import (
 	"github.com/kamilsk/check/errors"
 )
 
+// Supported output formats.
+const (
+	TextFormat  = "text"
+	JSONFormat  = "json"
+	JUnitFormat = "junit"
+	HTMLFormat  = "html"
+)
+
+// Supported groupings of links in the text output.
+const (
+	PageGrouping = "page"
+	LinkGrouping = "link"
+)
+
 const (
 	shaded  = "shaded"
 	success = "success"
@@ -25,8 +39,13 @@ const (
 
 var base = template.Must(template.New("entry").Parse(`
 {{- define "error" }}{{ with .Error }} -> ({{ . }}){{ end }}{{ end -}}
-{{- define "redirect" }}{{ with .Redirect }} -> {{ . }}{{ end }}{{ end -}}
-[{{ .StatusCode }}] {{ .Location }}{{ template "error" . }}{{ template "redirect" . -}}
+{{- define "redirect" }}{{ if .Hops }}{{ template "hops" . }}{{ else }}{{ with .Redirect }} -> {{ . }}{{ end }}{{ end }}{{ end -}}
+{{- define "hops" }}{{ range .Hops }} -> [{{ .StatusCode }}] {{ .Location }}{{ with .Error }} ({{ . }}){{ end }}{{ end }}
+{{- if .RedirectLoop }} (redirect loop){{ end }}{{ if .RedirectTruncated }} (redirect chain truncated){{ end }}{{ if .Downgrade }} (downgrade to http){{ end }}{{ end -}}
+{{- define "attempts" }}{{ with .Attempts }}{{/* ignore */}}{{ end }}{{ end -}}
+{{- define "kind" }}{{ with .Kind }}{{ if ne . "anchor" }} [{{ . }}]{{ end }}{{ end }}{{ end -}}
+[{{ if .Skipped }}---{{ else }}{{ .StatusCode }}{{ end }}] {{ .Location }}{{ template "kind" . }}
+{{- with .Skipped }} -> (skipped: {{ . }}){{ end }}{{ with .BrokenAnchor }} -> (broken anchor: #{{ . }}){{ end }}{{ template "error" . }}{{ template "redirect" . }}{{ template "attempts" . -}}
 `))
 
 // NewPrinter returns configured printer instance.
@@ -67,6 +86,51 @@ func DecodeOutput(enabled bool) func(*Printer) {
 	}
 }
 
+// FilterKinds limits output of links by the passed kinds,
+// links of all kinds are printed if nothing is passed.
+func FilterKinds(kinds ...string) func(*Printer) {
+	return func(p *Printer) {
+		p.kinds = set(kinds)
+	}
+}
+
+// OnlyClasses limits output of links by the passed classes of their statuses,
+// e.g. 4xx or error, links of all classes are printed if nothing is passed.
+func OnlyClasses(classes ...string) func(*Printer) {
+	return func(p *Printer) {
+		p.only = classesSet(classes)
+	}
+}
+
+// HideClasses prevents output of links with the passed classes of their statuses, e.g. 2xx.
+func HideClasses(classes ...string) func(*Printer) {
+	return func(p *Printer) {
+		p.hidden = classesSet(classes)
+	}
+}
+
+// ShowExcluded enables output of links excluded or ignored by URL filters.
+func ShowExcluded(enabled bool) func(*Printer) {
+	return func(p *Printer) {
+		p.excluded = enabled
+	}
+}
+
+// GroupBy sets the grouping of links in the text output:
+// by pages on which they are found or by distinct links with lists of such pages.
+func GroupBy(grouping string) func(*Printer) {
+	return func(p *Printer) {
+		p.grouping = grouping
+	}
+}
+
+// FormatOutput sets the output format of the printer.
+func FormatOutput(format string) func(*Printer) {
+	return func(p *Printer) {
+		p.format = format
+	}
+}
+
 // HideError prevents URL's error output.
 func HideError(disabled bool) func(*Printer) {
 	return func(p *Printer) {
@@ -85,6 +149,16 @@ func HideRedirect(disabled bool) func(*Printer) {
 	}
 }
 
+// ShowAttempts enables output of how many times a URL has been requested
+// if it's requested more than once.
+func ShowAttempts(enabled bool) func(*Printer) {
+	return func(p *Printer) {
+		if enabled {
+			unsafe.DoSilent(p.tpl.New("attempts").Parse("{{ if gt .Attempts 1 }} [attempts: {{ .Attempts }}]{{ end }}"))
+		}
+	}
+}
+
 // OutputForPrinting sets up printer output.
 func OutputForPrinting(output io.Writer) func(*Printer) {
 	return func(p *Printer) {
@@ -99,11 +173,17 @@ type Reporter interface {
 
 // Printer represents a printer.
 type Printer struct {
-	tpl     *template.Template
-	output  io.Writer
-	ink     map[string]*color.Color
-	decoder func(string) string
-	report  Reporter
+	tpl      *template.Template
+	format   string
+	grouping string
+	kinds    map[string]bool
+	only     map[string]bool
+	hidden   map[string]bool
+	excluded bool
+	output   io.Writer
+	ink      map[string]*color.Color
+	decoder  func(string) string
+	report   Reporter
 }
 
 // For prepares printer for passed report provider.
@@ -115,12 +195,34 @@ func (p *Printer) For(report Reporter) *Printer {
 // Print prints a report into the configured output.
 // Stdout is used as a fallback if the output is not set up.
 func (p *Printer) Print() error {
-	var blob = [1024]byte{}
-	w, buf := p.outOrStdout(), bytes.NewBuffer(blob[:0])
 	if p.report == nil {
 		return errors.Simple("nothing to print")
 	}
+	switch p.grouping {
+	case "", PageGrouping, LinkGrouping:
+	default:
+		return errors.Errorf("unsupported grouping %q", p.grouping)
+	}
+	w := p.outOrStdout()
+	switch p.format {
+	case "", TextFormat:
+		return p.printText(w)
+	case JSONFormat:
+		return p.printJSON(w)
+	case JUnitFormat:
+		return p.printJUnit(w)
+	case HTMLFormat:
+		return p.printHTML(w)
+	default:
+		return errors.Errorf("unsupported output format %q", p.format)
+	}
+}
+
+func (p *Printer) printText(w io.Writer) error {
+	var blob = [1024]byte{}
+	buf := bytes.NewBuffer(blob[:0])
 	for site := range p.report.Sites() {
+		site = p.filter(site)
 		if site.Error != nil {
 			p.critical().Fprintf(w, "report %q has error %q\n", site.Name, site.Error)
 			if stack := errors.StackTrace(site.Error); stack != nil {
@@ -128,36 +230,169 @@ func (p *Printer) Print() error {
 			}
 			continue
 		}
-		sort.Sort(pagesByLocation(site.Pages))
-		for _, page := range site.Pages {
-			last := len(page.Links) - 1
+		if p.grouping == LinkGrouping {
+			p.printReferences(w, buf, site)
+		} else {
+			p.printPages(w, buf, site)
+		}
+		p.printDiscrepancies(w, buf, fmt.Sprintf("found orphan pages on the site %q", site.Name), site.Orphans)
+		p.printDiscrepancies(w, buf, fmt.Sprintf("found pages missing from the sitemap of the site %q", site.Name), site.Unlisted)
+		p.printDiscrepancies(w, buf, fmt.Sprintf("fixed links since the baseline of the site %q", site.Name), site.Fixed)
+		for _, section := range []struct {
+			title    string
+			category string
+		}{
+			{"found insecure links on https pages of the site %q", InsecureLinkProblem},
+			{"found mixed content on https pages of the site %q", MixedContentProblem},
+			{"found problems on the site %q", ""},
+		} {
+			p.printProblems(w, fmt.Sprintf(section.title, site.Name), problemsOf(site.Problems, section.category))
+		}
+	}
+	return nil
+}
+
+func (p *Printer) printProblems(w io.Writer, title string, problems []ProblemEvent) {
+	if len(problems) == 0 {
+		return
+	}
+	p.critical().Fprintf(w, "%s\n", title)
+	for i, problem := range problems {
+		p.critical().Fprintf(w, "- [%d] %s `%+v`\n", i, problem.Message, problem.Context)
+	}
+}
+
+func (p *Printer) printPages(w io.Writer, buf *bytes.Buffer, site Site) {
+	sort.Sort(pagesByLocation(site.Pages))
+	for _, page := range site.Pages {
+		last := len(page.Links) - 1
+		{
+			buf.Reset()
+			unsafe.Ignore(p.tpl.Execute(buf, page))
+		}
+		p.typewriter(page.Link).Fprintf(w, "%s\n", p.decoder(buf.String()))
+		sort.Sort(linksByStatusCode(page.Links))
+		for i, link := range page.Links {
+			link := link
 			{
 				buf.Reset()
-				unsafe.Ignore(p.tpl.Execute(buf, page))
+				unsafe.Ignore(p.tpl.Execute(buf, link))
 			}
-			p.typewriter(page.Link).Fprintf(w, "%s\n", p.decoder(buf.String()))
-			sort.Sort(linksByStatusCode(page.Links))
-			for i, link := range page.Links {
-				link := link
-				{
-					buf.Reset()
-					unsafe.Ignore(p.tpl.Execute(buf, link))
-				}
-				if i == last {
-					p.typewriter(&link).Fprintf(w, "    └───%s\n", p.decoder(buf.String()))
-					continue
-				}
-				p.typewriter(&link).Fprintf(w, "    ├───%s\n", p.decoder(buf.String()))
+			if i == last {
+				p.typewriter(&link).Fprintf(w, "    └───%s\n", p.decoder(buf.String()))
+				continue
 			}
+			p.typewriter(&link).Fprintf(w, "    ├───%s\n", p.decoder(buf.String()))
+		}
+	}
+}
+
+// printReferences prints each distinct link once with pages on which it is found,
+// links are sorted by severity of their issues and then by count of such pages.
+func (p *Printer) printReferences(w io.Writer, buf *bytes.Buffer, site Site) {
+	refs := site.References()
+	sort.SliceStable(refs, func(i, j int) bool {
+		if rank1, rank2 := rank(refs[i].Link), rank(refs[j].Link); rank1 != rank2 {
+			return rank1 < rank2
 		}
-		if len(site.Problems) > 0 {
-			p.critical().Fprintf(w, "found problems on the site %q\n", site.Name)
-			for i, problem := range site.Problems {
-				p.critical().Fprintf(w, "- [%d] %s `%+v`\n", i, problem.Message, problem.Context)
+		return len(refs[i].Pages) > len(refs[j].Pages)
+	})
+	for _, ref := range refs {
+		if !p.shows(ref.Link) {
+			continue
+		}
+		link := ref.Link
+		{
+			buf.Reset()
+			unsafe.Ignore(p.tpl.Execute(buf, link))
+		}
+		p.typewriter(&link).Fprintf(w, "%s\n", p.decoder(buf.String()))
+		last := len(ref.Pages) - 1
+		for i, page := range ref.Pages {
+			if i == last {
+				p.typewriter(&link).Fprintf(w, "    └───%s\n", p.decoder(page))
+				continue
 			}
+			p.typewriter(&link).Fprintf(w, "    ├───%s\n", p.decoder(page))
 		}
 	}
-	return nil
+}
+
+func (p *Printer) printDiscrepancies(w io.Writer, buf *bytes.Buffer, title string, links []Link) {
+	if len(links) == 0 {
+		return
+	}
+	p.critical().Fprintf(w, "%s\n", title)
+	for _, link := range links {
+		link := link
+		{
+			buf.Reset()
+			unsafe.Ignore(p.tpl.Execute(buf, link))
+		}
+		p.typewriter(&link).Fprintf(w, "- %s\n", p.decoder(buf.String()))
+	}
+}
+
+// filter excludes links of not printed kinds and classes from the site,
+// links with issues known by the baseline, as well as links
+// excluded or ignored by URL filters if they are not shown.
+// Orphan, unlisted and fixed links are filtered by classes and URL filters too.
+// Pages without visible links are dropped if links are filtered by classes.
+// Links without a kind are considered as anchors.
+func (p *Printer) filter(site Site) Site {
+	pages := make([]*Page, 0, len(site.Pages))
+	for _, page := range site.Pages {
+		links := make([]Link, 0, len(page.Links))
+		for _, link := range page.Links {
+			kind := link.Kind
+			if kind == "" {
+				kind = AnchorKind
+			}
+			if len(p.kinds) > 0 && !p.kinds[kind] {
+				continue
+			}
+			if link.Known || !p.excluded && (link.Skipped == ExcludedSkipReason || link.Ignored) {
+				continue
+			}
+			if !p.shows(link) {
+				continue
+			}
+			links = append(links, link)
+		}
+		if len(links) == 0 && p.filtersClasses() {
+			continue
+		}
+		pages = append(pages, &Page{Link: page.Link, Links: links})
+	}
+	site.Pages = pages
+	site.Orphans = p.visible(site.Orphans)
+	site.Unlisted = p.visible(site.Unlisted)
+	site.Fixed = p.visible(site.Fixed)
+	return site
+}
+
+// visible returns links of printed classes which are not ignored.
+func (p *Printer) visible(links []Link) []Link {
+	if links == nil {
+		return nil
+	}
+	filtered := make([]Link, 0, len(links))
+	for _, link := range links {
+		if p.shows(link) && (p.excluded || !link.Ignored) {
+			filtered = append(filtered, link)
+		}
+	}
+	return filtered
+}
+
+// shows returns true if the class of the link's status is printed.
+func (p *Printer) shows(link Link) bool {
+	class := link.StatusClass()
+	return (len(p.only) == 0 || p.only[class]) && !p.hidden[class]
+}
+
+func (p *Printer) filtersClasses() bool {
+	return len(p.only) > 0 || len(p.hidden) > 0
 }
 
 func (p *Printer) critical() typewriter {
@@ -177,7 +412,7 @@ func (p *Printer) typewriter(link *Link) typewriter {
 		ok bool
 	)
 	switch {
-	case link == nil:
+	case link == nil, link.BrokenAnchor != "":
 		tw, ok = p.ink[danger]
 	case link.StatusCode >= 200 && link.StatusCode < 300:
 		if link.Internal {
@@ -196,6 +431,26 @@ func (p *Printer) typewriter(link *Link) typewriter {
 	return tw
 }
 
+// classesSet returns a set of classes of links' statuses resolving their aliases.
+func classesSet(classes []string) map[string]bool {
+	resolved := make([]string, 0, len(classes))
+	for _, class := range classes {
+		resolved = append(resolved, classOf(class))
+	}
+	return set(resolved)
+}
+
+func set(values []string) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+	index := make(map[string]bool, len(values))
+	for _, value := range values {
+		index[value] = true
+	}
+	return index
+}
+
 type typewriter interface {
 	Fprintf(io.Writer, string, ...interface{}) (int, error)
 }
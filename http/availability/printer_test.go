@@ -141,6 +141,110 @@ func TestPrinter(t *testing.T) {
 			assert.NoError,
 			"[200] https://kamil.samigullin.info/",
 		},
+		{
+			"with attempts",
+			func() *availability.Printer {
+				return availability.NewPrinter(availability.ShowAttempts(true), availability.OutputForPrinting(buf))
+			},
+			func() availability.Reporter {
+				m := &PrinterMock{}
+				data := make(chan availability.Site, 1)
+				data <- availability.Site{Pages: []*availability.Page{
+					{
+						&availability.Link{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/"},
+						[]availability.Link{
+							{StatusCode: http.StatusOK, Location: "https://github.com/kamilsk", Attempts: 3},
+						},
+					},
+				}}
+				close(data)
+				var pipe <-chan availability.Site = data
+				m.On("Sites").Return(pipe)
+				return m
+			},
+			assert.NoError,
+			"[200] https://github.com/kamilsk [attempts: 3]",
+		},
+		{
+			"with skipped",
+			func() *availability.Printer { return availability.NewPrinter(availability.OutputForPrinting(buf)) },
+			func() availability.Reporter {
+				m := &PrinterMock{}
+				data := make(chan availability.Site, 1)
+				data <- availability.Site{Pages: []*availability.Page{
+					{
+						&availability.Link{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/"},
+						[]availability.Link{
+							{Location: "https://github.com/kamilsk", Skipped: availability.ExternalSkipReason},
+						},
+					},
+				}}
+				close(data)
+				var pipe <-chan availability.Site = data
+				m.On("Sites").Return(pipe)
+				return m
+			},
+			assert.NoError,
+			"[---] https://github.com/kamilsk -> (skipped: external)",
+		},
+		{
+			"with redirect chain",
+			func() *availability.Printer { return availability.NewPrinter(availability.OutputForPrinting(buf)) },
+			func() availability.Reporter {
+				m := &PrinterMock{}
+				data := make(chan availability.Site, 1)
+				data <- availability.Site{Pages: []*availability.Page{
+					{
+						&availability.Link{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/"},
+						[]availability.Link{
+							{StatusCode: http.StatusMovedPermanently,
+								Location: "https://howilive.ru/", Redirect: "http://howilive.ru/en/",
+								Hops: []availability.Hop{
+									{StatusCode: http.StatusFound, Location: "http://howilive.ru/en/"},
+									{StatusCode: http.StatusFound, Location: "http://howilive.ru/ru/"},
+								},
+								RedirectLoop: true},
+						},
+					},
+				}}
+				close(data)
+				var pipe <-chan availability.Site = data
+				m.On("Sites").Return(pipe)
+				return m
+			},
+			assert.NoError,
+			"[301] https://howilive.ru/ -> [302] http://howilive.ru/en/ -> [302] http://howilive.ru/ru/" +
+				" (redirect loop) (downgrade to http)",
+		},
+		{
+			"filtered by kind",
+			func() *availability.Printer {
+				return availability.NewPrinter(
+					availability.FilterKinds(availability.ImageKind),
+					availability.OutputForPrinting(buf),
+				)
+			},
+			func() availability.Reporter {
+				m := &PrinterMock{}
+				data := make(chan availability.Site, 1)
+				data <- availability.Site{Pages: []*availability.Page{
+					{
+						&availability.Link{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/"},
+						[]availability.Link{
+							{StatusCode: http.StatusOK, Location: "https://github.com/kamilsk"},
+							{StatusCode: http.StatusNotFound, Location: "https://kamil.samigullin.info/logo.png",
+								Kind: availability.ImageKind},
+						},
+					},
+				}}
+				close(data)
+				var pipe <-chan availability.Site = data
+				m.On("Sites").Return(pipe)
+				return m
+			},
+			assert.NoError,
+			"    └───[404] https://kamil.samigullin.info/logo.png [image]\n",
+		},
 	}
 	for _, test := range tests {
 		tc := test
@@ -151,3 +255,153 @@ func TestPrinter(t *testing.T) {
 		})
 	}
 }
+
+func TestPrinter_groupByLink(t *testing.T) {
+	home := &availability.Page{
+		Link: &availability.Link{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/"},
+	}
+	en := &availability.Page{
+		Link: &availability.Link{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/en/"},
+	}
+	footer := availability.Link{StatusCode: http.StatusNotFound, Location: "https://kamil.samigullin.info/footer"}
+	home.Links = []availability.Link{
+		footer,
+		{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/en/", Internal: true},
+		{StatusCode: http.StatusOK, Location: "https://github.com/kamilsk"},
+	}
+	en.Links = []availability.Link{
+		footer,
+		{StatusCode: http.StatusInternalServerError, Location: "https://kamil.samigullin.info/api"},
+	}
+	report := func() availability.Reporter {
+		m := &PrinterMock{}
+		data := make(chan availability.Site, 1)
+		data <- availability.Site{Name: "kamil.samigullin.info", Pages: []*availability.Page{home, en}}
+		close(data)
+		var pipe <-chan availability.Site = data
+		m.On("Sites").Return(pipe)
+		return m
+	}
+
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(t, availability.NewPrinter(
+		availability.GroupBy(availability.LinkGrouping),
+		availability.OutputForPrinting(buf),
+	).For(report()).Print())
+	assert.Equal(t, `[500] https://kamil.samigullin.info/api
+    └───https://kamil.samigullin.info/en/
+[404] https://kamil.samigullin.info/footer
+    ├───https://kamil.samigullin.info/
+    └───https://kamil.samigullin.info/en/
+[200] https://github.com/kamilsk
+    └───https://kamil.samigullin.info/
+[200] https://kamil.samigullin.info/en/
+    └───https://kamil.samigullin.info/
+[200] https://kamil.samigullin.info/
+`, buf.String())
+
+	assert.Error(t, availability.NewPrinter(availability.GroupBy("site")).For(report()).Print())
+}
+
+func TestPrinter_filterClasses(t *testing.T) {
+	home := &availability.Page{
+		Link: &availability.Link{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/"},
+	}
+	en := &availability.Page{
+		Link: &availability.Link{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/en/"},
+	}
+	home.Links = []availability.Link{
+		{StatusCode: http.StatusNotFound, Location: "https://kamil.samigullin.info/404"},
+		{StatusCode: http.StatusFound, Location: "https://kamil.samigullin.info/goto", Redirect: "https://github.com/"},
+		{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/en/", Internal: true},
+	}
+	en.Links = []availability.Link{
+		{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/", Internal: true},
+	}
+	report := func() availability.Reporter {
+		m := &PrinterMock{}
+		data := make(chan availability.Site, 1)
+		data <- availability.Site{
+			Name:     "kamil.samigullin.info",
+			Pages:    []*availability.Page{home, en},
+			Orphans:  []availability.Link{{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/orphan"}},
+			Unlisted: []availability.Link{{StatusCode: http.StatusOK, Location: "https://kamil.samigullin.info/unlisted"}},
+			Fixed:    []availability.Link{{StatusCode: http.StatusNotFound, Location: "https://kamil.samigullin.info/fixed"}},
+		}
+		close(data)
+		var pipe <-chan availability.Site = data
+		m.On("Sites").Return(pipe)
+		return m
+	}
+
+	tests := []struct {
+		name     string
+		options  []func(*availability.Printer)
+		expected []string
+		hidden   []string
+	}{
+		{
+			"text",
+			[]func(*availability.Printer){availability.HideClasses(availability.SuccessClass)},
+			[]string{
+				"[200] https://kamil.samigullin.info/\n",
+				"    ├───[302] https://kamil.samigullin.info/goto -> https://github.com/\n",
+				"    └───[404] https://kamil.samigullin.info/404\n",
+				"- [404] https://kamil.samigullin.info/fixed\n",
+			},
+			[]string{
+				"https://kamil.samigullin.info/en/",
+				"https://kamil.samigullin.info/orphan",
+				"https://kamil.samigullin.info/unlisted",
+			},
+		},
+		{
+			"json",
+			[]func(*availability.Printer){
+				availability.OnlyClasses(availability.ClientErrorCategory),
+				availability.FormatOutput(availability.JSONFormat),
+			},
+			[]string{`"location":"https://kamil.samigullin.info/404"`, `"location":"https://kamil.samigullin.info/fixed"`},
+			[]string{
+				"https://kamil.samigullin.info/en/",
+				"https://kamil.samigullin.info/goto",
+				"https://kamil.samigullin.info/orphan",
+			},
+		},
+		{
+			"junit",
+			[]func(*availability.Printer){
+				availability.OnlyClasses(availability.ClientErrorCategory, availability.RedirectCategory),
+				availability.FormatOutput(availability.JUnitFormat),
+			},
+			[]string{`name="https://kamil.samigullin.info/404"`, `name="https://kamil.samigullin.info/goto"`},
+			[]string{`name="https://kamil.samigullin.info/"`, `name="https://kamil.samigullin.info/en/"`},
+		},
+		{
+			"html",
+			[]func(*availability.Printer){
+				availability.HideClasses(availability.SuccessClass, availability.RedirectClass),
+				availability.FormatOutput(availability.HTMLFormat),
+			},
+			[]string{`<li class="class-4xx">4xx: 1</li>`, "https://kamil.samigullin.info/fixed"},
+			[]string{"class-2xx\">", "https://kamil.samigullin.info/goto", "https://kamil.samigullin.info/unlisted"},
+		},
+	}
+	for _, test := range tests {
+		tc := test
+		t.Run(test.name, func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			options := append(tc.options, availability.OutputForPrinting(buf))
+			assert.NoError(t, availability.NewPrinter(options...).For(report()).Print())
+			for _, expected := range tc.expected {
+				assert.Contains(t, buf.String(), expected)
+			}
+			for _, hidden := range tc.hidden {
+				assert.NotContains(t, buf.String(), hidden)
+			}
+		})
+	}
+
+	assert.NoError(t, availability.ValidateClasses([]string{"2xx", "error", "skipped", "redirect"}))
+	assert.Error(t, availability.ValidateClasses([]string{"1xx"}))
+}
@@ -0,0 +1,123 @@
+package availability
+
+import (
+	"net/url"
+
+	"github.com/gocolly/colly/v2"
+
+	"github.com/kamilsk/check/errors"
+)
+
+// hopKey is a key of a followed hop in the context of its request.
+const hopKey = "hop"
+
+// Hop contains a status code of a response in a redirect chain and its URL.
+type Hop struct {
+	StatusCode int
+	Location   string
+	Error      error
+}
+
+// newRedirectTracer returns a tracer which sends requests by a copy of the collector,
+// so they share its transport, limits of concurrency and rate, cookies and timeout.
+func newRedirectTracer(c *colly.Collector, config CrawlerConfig) *redirectTracer {
+	if config.FollowRedirects < 1 {
+		return nil
+	}
+	collector := c.Clone()
+	collector.Async = false
+	collector.AllowURLRevisit = true
+	OnRequest(config.Credentials...)(collector)
+	collector.OnResponse(func(resp *colly.Response) {
+		resp.Ctx.Put(hopKey, redirectHop{status: resp.StatusCode})
+	})
+	collector.OnError(func(resp *colly.Response, err error) {
+		hop := redirectHop{status: resp.StatusCode}
+		if resp.Headers != nil {
+			hop.location = resp.Headers.Get(locationHeader)
+		}
+		if resp.StatusCode == 0 {
+			hop.err = err
+		}
+		resp.Ctx.Put(hopKey, hop)
+	})
+	return &redirectTracer{collector: collector, limit: config.FollowRedirects}
+}
+
+// redirectTracer follows redirects hop by hop with the method of the origin request.
+type redirectTracer struct {
+	collector *colly.Collector
+	limit     int
+}
+
+type redirectHop struct {
+	status   int
+	location string
+	err      error
+}
+
+// trace returns hops of the redirect chain started by the request,
+// true if the chain is looped and true if it's truncated by the limit of hops.
+// Nothing is returned if the tracer is not configured.
+func (t *redirectTracer) trace(req *colly.Request, redirect string) (hops []Hop, loop, truncated bool) {
+	if t == nil || redirect == "" {
+		return nil, false, false
+	}
+	current := req.URL
+	visited := map[string]bool{current.String(): true}
+	hops = make([]Hop, 0, t.limit)
+	for {
+		next, err := current.Parse(redirect)
+		if err != nil {
+			return append(hops, Hop{Location: redirect, Error: err}), false, false
+		}
+		location := next.String()
+		if visited[location] {
+			return hops, true, false
+		}
+		if len(hops) == t.limit {
+			return hops, false, true
+		}
+		visited[location] = true
+		hop, location := t.follow(req.Method, next)
+		hops = append(hops, hop)
+		if hop.Error != nil || location == "" || hop.StatusCode < 300 || hop.StatusCode >= 400 {
+			return hops, false, false
+		}
+		current, redirect = next, location
+	}
+}
+
+// follow sends a request and returns its hop and a value of the Location header.
+func (t *redirectTracer) follow(method string, u *url.URL) (Hop, string) {
+	hop := Hop{Location: u.String()}
+	ctx := colly.NewContext()
+	err := t.collector.Request(method, hop.Location, nil, ctx, nil)
+	result, found := ctx.GetAny(hopKey).(redirectHop)
+	if !found {
+		hop.Error = err
+		if hop.Error == nil {
+			hop.Error = errors.Errorf("request to %q is aborted", hop.Location)
+		}
+		return hop, ""
+	}
+	hop.StatusCode, hop.Error = result.status, result.err
+	return hop, result.location
+}
+
+// Downgrade returns true if the redirect chain leads from https to http.
+func (l Link) Downgrade() bool {
+	previous := l.Location
+	for _, hop := range l.Hops {
+		if isScheme(previous, "https") && isScheme(hop.Location, "http") {
+			return true
+		}
+		previous = hop.Location
+	}
+	return false
+}
+
+func isScheme(location, scheme string) bool {
+	u, err := url.Parse(location)
+	return err == nil && u.Scheme == scheme
+}
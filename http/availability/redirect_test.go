@@ -0,0 +1,154 @@
+package availability_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.octolab.org/unsafe"
+
+	"github.com/kamilsk/check/http/availability"
+)
+
+func TestCrawlerColly_followRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/a":
+			http.Redirect(rw, req, "/b", http.StatusMovedPermanently)
+		case "/b":
+			http.Redirect(rw, req, "/c", http.StatusFound)
+		case "/c":
+			rw.WriteHeader(http.StatusNotFound)
+		case "/loop":
+			http.Redirect(rw, req, "/pool", http.StatusFound)
+		case "/pool":
+			http.Redirect(rw, req, "/loop", http.StatusFound)
+		default:
+			unsafe.Ignore(tpl.Execute(rw, []struct {
+				Href string
+				Text string
+			}{{Href: "/a", Text: "chain"}, {Href: "/loop", Text: "loop"}}))
+		}
+	}))
+	defer server.Close()
+
+	type chain struct {
+		hops      []availability.Hop
+		loop      bool
+		truncated bool
+	}
+	tests := []struct {
+		name     string
+		limit    int
+		expected map[string]chain
+	}{
+		{"disabled", 0, map[string]chain{
+			server.URL + "/a":    {},
+			server.URL + "/loop": {},
+		}},
+		{"limited", 1, map[string]chain{
+			server.URL + "/a": {
+				hops:      []availability.Hop{{StatusCode: http.StatusFound, Location: server.URL + "/b"}},
+				truncated: true,
+			},
+			server.URL + "/loop": {
+				hops: []availability.Hop{{StatusCode: http.StatusFound, Location: server.URL + "/pool"}},
+				loop: true,
+			},
+		}},
+		{"traced", 10, map[string]chain{
+			server.URL + "/a": {hops: []availability.Hop{
+				{StatusCode: http.StatusFound, Location: server.URL + "/b"},
+				{StatusCode: http.StatusNotFound, Location: server.URL + "/c"},
+			}},
+			server.URL + "/loop": {
+				hops: []availability.Hop{{StatusCode: http.StatusFound, Location: server.URL + "/pool"}},
+				loop: true,
+			},
+		}},
+	}
+	for _, test := range tests {
+		tc := test
+		t.Run(test.name, func(t *testing.T) {
+			chains := make(map[string]chain)
+			crawler := availability.CrawlerColly(availability.CrawlerConfig{FollowRedirects: tc.limit})
+			events, err := collect(crawler, server.URL+"/")
+			assert.NoError(t, err)
+			for _, event := range events {
+				if e, is := event.(availability.ErrorEvent); is {
+					chains[e.Location] = chain{hops: e.Hops, loop: e.RedirectLoop, truncated: e.RedirectTruncated}
+				}
+			}
+			assert.Equal(t, tc.expected, chains)
+		})
+	}
+}
+
+func TestCrawlerColly_followRedirectsConfigured(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, peak int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > peak {
+			peak = inFlight
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		time.Sleep(5 * time.Millisecond)
+		step, err := strconv.Atoi(strings.Trim(req.URL.Path, "/"))
+		switch {
+		case err != nil:
+			links := make([]struct {
+				Href string
+				Text string
+			}, 10)
+			for i := range links {
+				links[i].Href, links[i].Text = fmt.Sprintf("/%d", (i+1)*10), "chain"
+			}
+			unsafe.Ignore(tpl.Execute(rw, links))
+		case step%10 < 3:
+			http.Redirect(rw, req, fmt.Sprintf("/%d", step+1), http.StatusFound)
+		default:
+			rw.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+	resolve, err := availability.ParseResolve([]string{"www.example.test:" + u.Port() + ":" + u.Hostname()})
+	assert.NoError(t, err)
+	entry := "http://www.example.test:" + u.Port()
+
+	buf := bytes.NewBuffer(nil)
+	report := availability.NewReport(availability.CrawlerForSites(availability.CrawlerColly(
+		availability.CrawlerConfig{Resolve: resolve, Concurrency: 2, FollowRedirects: 2},
+	))).For([]string{entry + "/"}).Fill()
+	assert.NoError(t, availability.NewPrinter(availability.OutputForPrinting(buf)).For(report).Print())
+	assert.Contains(t, buf.String(),
+		fmt.Sprintf("[302] %[1]s/10 -> (Found) -> [302] %[1]s/11 -> [302] %[1]s/12 (redirect chain truncated)", entry))
+	assert.LessOrEqual(t, peak, 2)
+}
+
+func TestLink_Downgrade(t *testing.T) {
+	assert.True(t, availability.Link{
+		Location: "https://kamil.samigullin.info/",
+		Hops:     []availability.Hop{{Location: "https://octolab.org/"}, {Location: "http://octolab.org/"}},
+	}.Downgrade())
+	assert.False(t, availability.Link{
+		Location: "http://kamil.samigullin.info/",
+		Hops:     []availability.Hop{{Location: "https://kamil.samigullin.info/"}},
+	}.Downgrade())
+}
@@ -1,7 +1,11 @@
 package availability
 
 import (
+	"fmt"
 	"net/url"
+	"regexp"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/kamilsk/check/errors"
@@ -23,11 +27,33 @@ func CrawlerForSites(crawler Crawler) func(*Report) {
 	}
 }
 
+// CrawlerForSite sets the website crawler to a report builder
+// for the website with the passed URL, it overrides one set by CrawlerForSites.
+func CrawlerForSite(rawURL string, crawler Crawler) func(*Report) {
+	return func(r *Report) {
+		if r.crawlers == nil {
+			r.crawlers = make(map[string]Crawler)
+		}
+		r.crawlers[rawURL] = crawler
+	}
+}
+
+// ConcurrentSites sets how many websites can be fetched at the same time.
+func ConcurrentSites(limit int) func(*Report) {
+	return func(r *Report) {
+		r.concurrency = limit
+	}
+}
+
 // Report represents a report builder.
 type Report struct {
-	crawler Crawler
-	sites   []*Site
-	ready   chan Site
+	crawler     Crawler
+	crawlers    map[string]Crawler
+	baseline    *Baseline
+	ignore      []*regexp.Regexp
+	concurrency int
+	sites       []*Site
+	ready       chan Site
 }
 
 // For prepares report builder for passed websites' URLs.
@@ -40,25 +66,39 @@ func (r *Report) For(rawURLs []string) *Report {
 }
 
 // Fill starts to fetch sites and prepared them for reading.
+// Each site is available for reading as soon as it is fetched.
 func (r *Report) Fill() *Report {
 	r.ready = make(chan Site, len(r.sites))
-	for _, site := range r.sites {
-		site.Error = site.Fetch(r.crawler)
-		{
-			copied := *site
-			pages := make([]*Page, 0, len(site.Pages))
-			for _, page := range site.Pages {
-				page := *page
-				pages = append(pages, &page)
-				links := make([]Link, len(page.Links))
-				copy(links, page.Links)
-				page.Links = links
+	limit := r.concurrency
+	if limit < 1 {
+		limit = 1
+	}
+	wg, queue := &sync.WaitGroup{}, make(chan struct{}, limit)
+	unexpected := make([]error, len(r.sites))
+	for i, site := range r.sites {
+		wg.Add(1)
+		queue <- struct{}{}
+		go func(i int, site *Site) {
+			defer wg.Done()
+			defer func() { <-queue }()
+			defer errors.Recover(&unexpected[i])
+			crawler, specified := r.crawlers[site.entry]
+			if !specified {
+				crawler = r.crawler
 			}
-			copied.Pages = pages
-			r.ready <- copied
-		}
+			site.Error = site.Fetch(crawler)
+			r.baseline.compare(site)
+			ignore(site, r.ignore)
+			r.ready <- site.copy()
+		}(i, site)
 	}
+	wg.Wait()
 	close(r.ready)
+	for _, err := range unexpected {
+		if err != nil {
+			panic(err)
+		}
+	}
 	return r
 }
 
@@ -72,6 +112,7 @@ func NewSite(rawURL string) *Site {
 	u, err := url.Parse(rawURL)
 	return &Site{
 		url:   u,
+		entry: rawURL,
 		Name:  hostOrRawURL(u, rawURL),
 		Error: errors.Wrapf(err, "parse rawURL %q for report", rawURL),
 	}
@@ -79,12 +120,20 @@ func NewSite(rawURL string) *Site {
 
 // Site contains a meta information about a website.
 type Site struct {
-	url *url.URL
+	url   *url.URL
+	entry string
 
 	Name     string
 	Error    error
 	Pages    []*Page
 	Problems []ProblemEvent
+
+	// Orphans contains pages listed in the sitemap but not linked from anywhere.
+	Orphans []Link
+	// Unlisted contains walked pages missing from the sitemap.
+	Unlisted []Link
+	// Fixed contains links broken in the baseline which are found without issues.
+	Fixed []Link
 }
 
 // Fetch runs the website crawler and starts listen its events to build a website tree.
@@ -112,19 +161,43 @@ func (s *Site) Fetch(crawler Crawler) error {
 	return s.Error
 }
 
+func (s *Site) copy() Site {
+	copied := *s
+	pages := make([]*Page, 0, len(s.Pages))
+	for _, page := range s.Pages {
+		page := *page
+		pages = append(pages, &page)
+		links := make([]Link, len(page.Links))
+		copy(links, page.Links)
+		page.Links = links
+	}
+	copied.Pages = pages
+	return copied
+}
+
 func (s *Site) listen(events <-chan event) {
 	links := make(map[string]*Link)
 	pages := make(map[string]*Page)
-	linkToPage := make([][2]string, 0, 512)
+	anchors := make(map[string]map[string]bool)
+	fragments := make([]FragmentEvent, 0, 8)
+	var sitemap []string
+	linkToPage := make([]WalkEvent, 0, 512)
+	reported := make(map[string]bool)
 	for event := range events {
 		switch e := event.(type) {
 		case ErrorEvent:
 			if _, exists := links[e.Location]; !exists {
 				links[e.Location] = &Link{
-					StatusCode: e.StatusCode,
-					Location:   e.Location,
-					Redirect:   e.Redirect,
-					Error:      e.Error,
+					StatusCode:   e.StatusCode,
+					Location:     e.Location,
+					Redirect:     e.Redirect,
+					Error:        e.Error,
+					Timeout:      e.Timeout,
+					Attempts:     e.Attempts,
+					Hops:         e.Hops,
+					RedirectLoop: e.RedirectLoop,
+
+					RedirectTruncated: e.RedirectTruncated,
 				}
 			}
 		case ResponseEvent:
@@ -132,14 +205,44 @@ func (s *Site) listen(events <-chan event) {
 				links[e.Location] = &Link{
 					StatusCode: e.StatusCode,
 					Location:   e.Location,
+					Attempts:   e.Attempts,
+				}
+			}
+		case SkipEvent:
+			if _, exists := links[e.Location]; !exists {
+				links[e.Location] = &Link{
+					Location: e.Location,
+					Skipped:  e.Reason,
 				}
 			}
 		case WalkEvent:
 			if _, exists := pages[e.Page]; !exists {
 				pages[e.Page] = &Page{Links: make([]Link, 0, 8)}
 			}
-			linkToPage = append(linkToPage, [2]string{e.Href, e.Page})
+			linkToPage = append(linkToPage, e)
+		case AnchorsEvent:
+			anchors[e.Page] = make(map[string]bool, len(e.Anchors))
+			for _, anchor := range e.Anchors {
+				anchors[e.Page][anchor] = true
+			}
+		case FragmentEvent:
+			if _, exists := pages[e.Page]; !exists {
+				pages[e.Page] = &Page{Links: make([]Link, 0, 8)}
+			}
+			fragments = append(fragments, e)
+		case SitemapEvent:
+			if sitemap == nil {
+				sitemap = make([]string, 0, len(e.Pages))
+			}
+			sitemap = append(sitemap, e.Pages...)
 		case ProblemEvent:
+			if e.Category != "" {
+				key := e.Category + fmt.Sprintf("%+v", e.Context)
+				if reported[key] {
+					continue
+				}
+				reported[key] = true
+			}
 			s.Problems = append(s.Problems, e)
 		default:
 			panic(errors.Errorf("panic: unexpected event type %T", e))
@@ -149,23 +252,105 @@ func (s *Site) listen(events <-chan event) {
 	s.Pages = make([]*Page, 0, len(pages))
 	for location, page := range pages {
 		page.Link = links[location]
+		if page.Link != nil {
+			// every crawled page belongs to the website
+			page.Link.Internal = true
+		}
 		s.Pages = append(s.Pages, page)
 		barrier[page] = make(map[*Link]struct{})
 	}
-	for _, linkAndPage := range linkToPage {
-		linkLocation, pageLocation := linkAndPage[0], linkAndPage[1]
-		link := links[linkLocation]
-		page := pages[pageLocation]
+	for _, walk := range linkToPage {
+		link := links[walk.Href]
+		page := pages[walk.Page]
 		if _, exists := barrier[page][link]; !exists {
 			barrier[page][link] = struct{}{}
 			{
 				link := *link
 				link.Page = page
-				link.Internal = hasSameHost(page.Link.Location, link.Location)
+				link.Kind = walk.Kind
+				link.Internal = walk.Internal || hasSameHost(page.Link.Location, link.Location)
 				page.Links = append(page.Links, link)
 			}
 		}
 	}
+	verifyFragments(pages, links, anchors, fragments)
+	if sitemap != nil {
+		s.compare(sitemap, links, linkToPage)
+	}
+}
+
+// compare finds discrepancies between the sitemap and the link graph of the website.
+func (s *Site) compare(sitemap []string, links map[string]*Link, linkToPage []WalkEvent) {
+	listed := make(map[string]bool, len(sitemap))
+	for _, location := range sitemap {
+		listed[location] = true
+	}
+	referenced := make(map[string]bool, len(linkToPage))
+	for _, walk := range linkToPage {
+		referenced[walk.Href] = true
+	}
+	s.Orphans, s.Unlisted = make([]Link, 0, 4), make([]Link, 0, 4)
+	for location := range listed {
+		if link := links[location]; link != nil && !referenced[location] && location != s.url.String() {
+			s.Orphans = append(s.Orphans, *link)
+		}
+	}
+	// pages without links are missing from s.Pages, so walked anchors are taken into account too
+	crawled := make(map[string]*Link, len(s.Pages)+len(linkToPage))
+	for _, page := range s.Pages {
+		if page.Link != nil {
+			crawled[page.Location] = page.Link
+		}
+	}
+	for _, walk := range linkToPage {
+		if walk.Kind == AnchorKind || walk.Kind == "" {
+			if link := links[walk.Href]; link != nil {
+				crawled[walk.Href] = link
+			}
+		}
+	}
+	for location, link := range crawled {
+		success := link.StatusCode >= 200 && link.StatusCode < 300
+		if success && !listed[location] && hasSameHost(s.url.String(), location) {
+			s.Unlisted = append(s.Unlisted, *link)
+		}
+	}
+	sort.Slice(s.Orphans, func(i, j int) bool { return s.Orphans[i].Location < s.Orphans[j].Location })
+	sort.Slice(s.Unlisted, func(i, j int) bool { return s.Unlisted[i].Location < s.Unlisted[j].Location })
+}
+
+// verifyFragments adds links with broken anchors to pages on which they are found.
+// Fragments of pages which are not parsed can't be verified and are ignored.
+func verifyFragments(
+	pages map[string]*Page,
+	links map[string]*Link,
+	anchors map[string]map[string]bool,
+	fragments []FragmentEvent,
+) {
+	barrier := make(map[string]struct{})
+	for _, fragment := range fragments {
+		known, parsed := anchors[fragment.Href]
+		if !parsed || known[fragment.Fragment] || isTopFragment(fragment.Fragment) {
+			continue
+		}
+		location := fragment.Href + "#" + fragment.Fragment
+		if _, exists := barrier[fragment.Page+" "+location]; exists {
+			continue
+		}
+		barrier[fragment.Page+" "+location] = struct{}{}
+		link := Link{
+			Location:     location,
+			Kind:         AnchorKind,
+			BrokenAnchor: fragment.Fragment,
+		}
+		if target := links[fragment.Href]; target != nil {
+			link.StatusCode = target.StatusCode
+		}
+		page := pages[fragment.Page]
+		link.Page = page
+		link.Internal = true
+		page.Links = append(page.Links, link)
+	}
 }
 
 // Page contains meta information about a website page.
@@ -176,12 +361,106 @@ type Page struct {
 
 // Link contains meta information about a web link.
 type Link struct {
-	Page       *Page
-	Internal   bool
-	StatusCode int
-	Location   string
-	Redirect   string
-	Error      error
+	Page         *Page
+	Internal     bool
+	StatusCode   int
+	Location     string
+	Redirect     string
+	Error        error
+	Timeout      bool
+	Attempts     int
+	Skipped      string
+	Kind         string
+	BrokenAnchor string
+	Hops         []Hop
+	RedirectLoop bool
+	// RedirectTruncated is true if the redirect chain is not traced
+	// to its end because of the limit of hops.
+	RedirectTruncated bool
+	Known             bool
+	// Ignored is true if the link matches URL filters of the report.
+	Ignored bool
+}
+
+// Reference contains a link and locations of all pages on which it is found.
+type Reference struct {
+	Link
+	Pages []string
+}
+
+// References returns distinct links of the website sorted by their location.
+func (s Site) References() []Reference {
+	index := make(map[string]int)
+	refs := make([]Reference, 0, len(s.Pages))
+	for _, page := range s.Pages {
+		if page.Link != nil {
+			if _, exists := index[page.Location]; !exists {
+				index[page.Location] = len(refs)
+				refs = append(refs, Reference{Link: *page.Link})
+			}
+		}
+		for _, link := range page.Links {
+			i, exists := index[link.Location]
+			if !exists {
+				i = len(refs)
+				index[link.Location] = i
+				refs = append(refs, Reference{Link: link})
+			}
+			if page.Link != nil {
+				refs[i].Pages = append(refs[i].Pages, page.Location)
+			}
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Location < refs[j].Location })
+	for _, ref := range refs {
+		sort.Strings(ref.Pages)
+	}
+	return refs
+}
+
+// Category returns a category of the link's state
+// or an empty string if the link is available.
+func (l Link) Category() string {
+	switch {
+	case l.BrokenAnchor != "":
+		return AnchorCategory
+	case l.Timeout:
+		return TimeoutCategory
+	case l.StatusCode >= 500:
+		return ServerErrorCategory
+	case l.StatusCode >= 400:
+		return ClientErrorCategory
+	case l.StatusCode >= 300:
+		return RedirectCategory
+	case l.Error != nil && l.StatusCode < 200:
+		return ErrorCategory
+	}
+	return ""
+}
+
+// StatusClass returns a class of the link's status, e.g. 2xx or 4xx,
+// timeout or error for failed requests, anchor for broken anchors
+// and skipped for not requested links.
+func (l Link) StatusClass() string {
+	switch {
+	case l.Skipped != "":
+		return SkippedClass
+	case l.BrokenAnchor != "":
+		return AnchorCategory
+	case l.StatusCode >= 100 && l.StatusCode < 600:
+		return fmt.Sprintf("%dxx", l.StatusCode/100)
+	case l.Timeout:
+		return TimeoutCategory
+	}
+	return ErrorCategory
+}
+
+func isBroken(link Link) bool {
+	switch link.Category() {
+	case ClientErrorCategory, ServerErrorCategory, ErrorCategory, TimeoutCategory, AnchorCategory:
+		return true
+	}
+	return false
 }
 
 func hostOrRawURL(u *url.URL, raw string) string {
@@ -191,6 +470,23 @@ func hostOrRawURL(u *url.URL, raw string) string {
 	return u.Host
 }
 
+// problemsOf returns problems of the category, unexpected errors have the empty one.
+func problemsOf(problems []ProblemEvent, category string) []ProblemEvent {
+	filtered := make([]ProblemEvent, 0, len(problems))
+	for _, problem := range problems {
+		if problem.Category == category {
+			filtered = append(filtered, problem)
+		}
+	}
+	return filtered
+}
+
+// isTopFragment returns true if the fragment refers to the top of a document
+// and does not require a target.
+func isTopFragment(fragment string) bool {
+	return fragment == "" || strings.EqualFold(fragment, "top")
+}
+
 func hasSameHost(link1, link2 string) bool {
 	u1, _ := url.Parse(link1)
 	u2, _ := url.Parse(link2)
@@ -209,36 +505,94 @@ func NewReadableEventBus(size int) chan event {
 // EventBus is a write-only channel to communicate between a website crawler and a report builder.
 type EventBus chan<- event
 
-// ErrorEvent contains a response' status code, its URL and an encountered error.
+// ErrorEvent contains a response' status code, its URL, an encountered error,
+// whether the request is timed out, how many times it has been sent
+// and a traced redirect chain.
 type ErrorEvent struct {
 	event
 
-	StatusCode int
-	Location   string
-	Redirect   string
-	Error      error
+	StatusCode   int
+	Location     string
+	Redirect     string
+	Error        error
+	Timeout      bool
+	Attempts     int
+	Hops         []Hop
+	RedirectLoop bool
+
+	RedirectTruncated bool
 }
 
-// ResponseEvent contains a response' status code and its URL.
+// ResponseEvent contains a response' status code, its URL
+// and how many times the request has been sent.
 type ResponseEvent struct {
 	event
 
 	StatusCode int
 	Location   string
+	Attempts   int
 }
 
-// WalkEvent contains information about a page and a link located on it.
+// SkipEvent contains a URL and a reason why it's not requested.
+type SkipEvent struct {
+	event
+
+	Location string
+	Reason   string
+}
+
+// AnchorsEvent contains element ids and anchor names found on a page.
+type AnchorsEvent struct {
+	event
+
+	Page    string
+	Anchors []string
+}
+
+// FragmentEvent contains information about a page
+// and a reference to a fragment of a target page located on it.
+type FragmentEvent struct {
+	event
+
+	Page     string
+	Href     string
+	Fragment string
+}
+
+// SitemapEvent contains locations of pages listed in sitemaps of a website.
+type SitemapEvent struct {
+	event
+
+	Pages []string
+}
+
+// WalkEvent contains information about a page, a link located on it
+// and a kind of the link, e.g. an anchor or an image.
 type WalkEvent struct {
 	event
 
 	Page string
 	Href string
+	Kind string
+	// Internal is true if the link belongs to the website by the crawler's configuration,
+	// links to the host of the page are always internal.
+	Internal bool
 }
 
-// ProblemEvent contains information about unexpected error.
+// Categories of problems found on pages, unexpected errors have no category.
+const (
+	// InsecureLinkProblem is a link to an http URL located on an https page.
+	InsecureLinkProblem = "insecure-link"
+	// MixedContentProblem is a resource with an http URL loaded by an https page.
+	MixedContentProblem = "mixed-content"
+)
+
+// ProblemEvent contains information about unexpected error
+// or an issue of the category found on a page.
 type ProblemEvent struct {
 	event
 
-	Message string
-	Context interface{}
+	Category string
+	Message  string
+	Context  interface{}
 }
@@ -106,6 +106,45 @@ func TestReporter(t *testing.T) {
 	}
 }
 
+func TestReporter_concurrentSites(t *testing.T) {
+	crawler := &CrawlerMock{shift: func(to availability.EventBus) {
+		to <- availability.ResponseEvent{StatusCode: http.StatusOK, Location: "http://test.dev/"}
+		to <- availability.WalkEvent{Page: "http://test.dev/", Href: "http://test.dev/"}
+		close(to)
+	}}
+	crawler.On("Visit", mock.Anything, mock.Anything).Return(nil)
+	report := availability.NewReport(availability.CrawlerForSites(crawler), availability.ConcurrentSites(2))
+
+	names := make([]string, 0, 3)
+	for site := range report.For([]string{"http://a.dev/", "http://b.dev/", "http://c.dev/"}).Fill().Sites() {
+		assert.NoError(t, site.Error)
+		assert.Len(t, site.Pages, 1)
+		names = append(names, site.Name)
+	}
+	assert.ElementsMatch(t, []string{"a.dev", "b.dev", "c.dev"}, names)
+}
+
+func TestReporter_crawlerForSite(t *testing.T) {
+	shift := func(to availability.EventBus) {
+		to <- availability.ResponseEvent{StatusCode: http.StatusOK, Location: "http://test.dev/"}
+		to <- availability.WalkEvent{Page: "http://test.dev/", Href: "http://test.dev/"}
+		close(to)
+	}
+	defaults, specific := &CrawlerMock{shift: shift}, &CrawlerMock{shift: shift}
+	defaults.On("Visit", "http://a.dev/", mock.Anything).Return(nil)
+	specific.On("Visit", "http://b.dev/", mock.Anything).Return(nil)
+	report := availability.NewReport(
+		availability.CrawlerForSites(defaults),
+		availability.CrawlerForSite("http://b.dev/", specific),
+	)
+
+	for site := range report.For([]string{"http://a.dev/", "http://b.dev/"}).Fill().Sites() {
+		assert.NoError(t, site.Error)
+	}
+	defaults.AssertExpectations(t)
+	specific.AssertExpectations(t)
+}
+
 func TestReporter_handlePanic(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,169 @@
+package availability
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+
+	"github.com/kamilsk/check/errors"
+)
+
+const (
+	retryAfterHeader   = "Retry-After"
+	retryAfterAttempts = 3
+	retryAfterLimit    = time.Minute
+)
+
+// Supported kinds of retryable network errors.
+// Status codes are specified by their values, e.g. 503, or classes, e.g. 5xx.
+const (
+	RetryOnTimeout    = "timeout"
+	RetryOnConnection = "connection"
+	RetryOnError      = "error"
+)
+
+// DefaultRetryOn contains conditions of retries used if nothing is specified.
+var DefaultRetryOn = []string{RetryOnTimeout, RetryOnConnection, "502", "503", "504"}
+
+// ValidateRetryOn checks that all passed conditions of retries are supported.
+func ValidateRetryOn(conditions []string) error {
+	for _, condition := range conditions {
+		switch condition {
+		case RetryOnTimeout, RetryOnConnection, RetryOnError:
+			continue
+		}
+		if _, err := strconv.Atoi(condition); err == nil {
+			continue
+		}
+		if len(condition) == 3 && strings.HasSuffix(condition, "xx") && condition[0] >= '1' && condition[0] <= '5' {
+			continue
+		}
+		return errors.Errorf("unsupported retry condition %q", condition)
+	}
+	return nil
+}
+
+func newRetrier(config CrawlerConfig) *retrier {
+	r := &retrier{retries: config.Retries, backoff: config.Backoff, conditions: make(map[string]bool)}
+	conditions := config.RetryOn
+	if len(conditions) == 0 {
+		conditions = DefaultRetryOn
+	}
+	for _, condition := range conditions {
+		r.conditions[condition] = true
+	}
+	return r
+}
+
+// retrier decides whether a failed request should be sent again.
+// Attempts are counted in the crawling context shared by all requests.
+type retrier struct {
+	retries    int
+	backoff    time.Duration
+	conditions map[string]bool
+}
+
+// retry sends the request of the response again if the response
+// is retryable and returns true in this case.
+func (r *retrier) retry(resp *colly.Response, err error) bool {
+	attempts := attempts(resp.Request)
+	delay, retryable := r.delay(resp, err, attempts)
+	if !retryable {
+		return false
+	}
+	resp.Request.Ctx.Put(attemptsKey(resp.Request), attempts+1)
+	time.Sleep(delay)
+	return isRequested(resp.Request.Retry())
+}
+
+func (r *retrier) delay(resp *colly.Response, err error, attempts int) (time.Duration, bool) {
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if resp.Headers != nil {
+			delay, present := retryAfter(resp.Headers.Get(retryAfterHeader), time.Now())
+			if present {
+				limit := retryAfterAttempts
+				if r.retries > limit {
+					limit = r.retries
+				}
+				return delay, delay <= retryAfterLimit && attempts <= limit
+			}
+		}
+	}
+	if attempts > r.retries || !r.matches(resp.StatusCode, err) {
+		return 0, false
+	}
+	return r.backoff << uint(attempts-1), true
+}
+
+func (r *retrier) matches(code int, err error) bool {
+	if code != 0 {
+		return r.conditions[strconv.Itoa(code)] || r.conditions[fmt.Sprintf("%dxx", code/100)]
+	}
+	if err == nil {
+		return false
+	}
+	switch {
+	case r.conditions[RetryOnError]:
+		return true
+	case r.conditions[RetryOnTimeout] && isTimeout(err):
+		return true
+	case r.conditions[RetryOnConnection] && isConnectionFailure(err):
+		return true
+	}
+	return false
+}
+
+// attempts returns how many times the request has been sent.
+func attempts(req *colly.Request) int {
+	if count, is := req.Ctx.GetAny(attemptsKey(req)).(int); is {
+		return count
+	}
+	return 1
+}
+
+func attemptsKey(req *colly.Request) string {
+	return "attempts:" + req.Method + ":" + req.URL.String()
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func isConnectionFailure(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryAfter parses a value of the Retry-After header,
+// which contains a delay in seconds or an HTTP date.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if delay := date.Sub(now); delay > 0 {
+		return delay, true
+	}
+	return 0, true
+}
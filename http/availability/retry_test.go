@@ -0,0 +1,115 @@
+package availability_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.octolab.org/unsafe"
+
+	"github.com/kamilsk/check/http/availability"
+)
+
+func TestCrawlerColly_retryAfter(t *testing.T) {
+	var busy, overloaded int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/busy":
+			if atomic.AddInt32(&busy, 1) <= 2 {
+				rw.Header().Set("Retry-After", "0")
+				rw.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			rw.WriteHeader(http.StatusOK)
+		case "/overloaded":
+			atomic.AddInt32(&overloaded, 1)
+			rw.Header().Set("Retry-After", time.Now().UTC().Format(http.TimeFormat))
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			unsafe.Ignore(tpl.Execute(rw, []struct {
+				Href string
+				Text string
+			}{{Href: "/busy", Text: "busy"}, {Href: "/overloaded", Text: "overloaded"}}))
+		}
+	}))
+	defer server.Close()
+
+	statuses := make(map[string][]int)
+	crawler := availability.CrawlerColly(availability.CrawlerConfig{Delay: time.Millisecond, Jitter: time.Millisecond})
+	events, err := collect(crawler, server.URL+"/")
+	assert.NoError(t, err)
+	for _, event := range events {
+		switch e := event.(type) {
+		case availability.ErrorEvent:
+			statuses[e.Location] = append(statuses[e.Location], e.StatusCode)
+		case availability.ResponseEvent:
+			statuses[e.Location] = append(statuses[e.Location], e.StatusCode)
+		}
+	}
+
+	assert.Equal(t, []int{http.StatusOK}, statuses[server.URL+"/busy"])
+	assert.Equal(t, int32(3), atomic.LoadInt32(&busy))
+	assert.Equal(t, []int{http.StatusServiceUnavailable}, statuses[server.URL+"/overloaded"])
+	assert.Equal(t, int32(4), atomic.LoadInt32(&overloaded))
+}
+
+func TestCrawlerColly_retries(t *testing.T) {
+	refused := httptest.NewServer(http.NotFoundHandler())
+	refused.Close()
+
+	var flaky int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/flaky":
+			if atomic.AddInt32(&flaky, 1) <= 2 {
+				rw.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			rw.WriteHeader(http.StatusOK)
+		case "/broken":
+			rw.WriteHeader(http.StatusInternalServerError)
+		default:
+			unsafe.Ignore(tpl.Execute(rw, []struct {
+				Href string
+				Text string
+			}{
+				{Href: "/flaky", Text: "flaky"},
+				{Href: "/broken", Text: "broken"},
+				{Href: refused.URL + "/", Text: "refused"},
+			}))
+		}
+	}))
+	defer server.Close()
+
+	attempts := make(map[string]int)
+	crawler := availability.CrawlerColly(availability.CrawlerConfig{
+		Retries: 2,
+		Backoff: time.Millisecond,
+		RetryOn: []string{availability.RetryOnConnection, "502"},
+	})
+	events, err := collect(crawler, server.URL+"/")
+	assert.NoError(t, err)
+	for _, event := range events {
+		switch e := event.(type) {
+		case availability.ErrorEvent:
+			attempts[e.Location] += e.Attempts
+		case availability.ResponseEvent:
+			attempts[e.Location] += e.Attempts
+		}
+	}
+
+	assert.Equal(t, 1, attempts[server.URL+"/"])
+	assert.Equal(t, 3, attempts[server.URL+"/flaky"])
+	assert.Equal(t, 1, attempts[server.URL+"/broken"])
+	assert.Equal(t, 3, attempts[refused.URL+"/"])
+}
+
+func TestValidateRetryOn(t *testing.T) {
+	assert.NoError(t, availability.ValidateRetryOn(availability.DefaultRetryOn))
+	assert.NoError(t, availability.ValidateRetryOn([]string{availability.RetryOnError, "5xx", "429"}))
+	assert.Error(t, availability.ValidateRetryOn([]string{"reset"}))
+	assert.Error(t, availability.ValidateRetryOn([]string{"9xx"}))
+}
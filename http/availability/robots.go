@@ -0,0 +1,114 @@
+package availability
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/temoto/robotstxt"
+	"go.octolab.org/unsafe"
+)
+
+// RobotsSkipReason is a reason of not requested links disallowed by robots.txt.
+const RobotsSkipReason = "robots"
+
+const (
+	// robotsMaxSize limits a size of a robots.txt file.
+	robotsMaxSize = 500 << 10
+	// robotsTimeout limits a duration of a robots.txt request.
+	robotsTimeout = 10 * time.Second
+)
+
+func newRobotsChecker(config CrawlerConfig, bus EventBus) *robotsChecker {
+	if !config.RespectRobots {
+		return nil
+	}
+	return &robotsChecker{
+		client:      &http.Client{Timeout: robotsTimeout, Transport: config.transport},
+		userAgent:   config.UserAgent,
+		credentials: config.Credentials,
+		bus:         bus,
+		hosts:       make(map[string]*robotsEntry),
+	}
+}
+
+// robotsChecker tests links against robots.txt of their hosts
+// for the crawler's user agent. Each robots.txt is fetched only once,
+// links of other hosts don't wait for it.
+// If robots.txt is unavailable, all links of its host are allowed.
+type robotsChecker struct {
+	mu          sync.Mutex
+	client      *http.Client
+	userAgent   string
+	credentials []Credential
+	bus         EventBus
+	hosts       map[string]*robotsEntry
+}
+
+type robotsEntry struct {
+	once   sync.Once
+	robots *robotstxt.RobotsData
+}
+
+// allowed returns true if the link is allowed to be requested.
+// Links are always allowed if the checker is not configured.
+func (r *robotsChecker) allowed(u *url.URL) bool {
+	if r == nil {
+		return true
+	}
+	robots := r.robots(u)
+	if robots == nil {
+		return true
+	}
+	path := u.EscapedPath()
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return robots.TestAgent(path, r.userAgent)
+}
+
+func (r *robotsChecker) robots(u *url.URL) *robotstxt.RobotsData {
+	host := u.Scheme + "://" + u.Host
+	r.mu.Lock()
+	entry, present := r.hosts[host]
+	if !present {
+		entry = &robotsEntry{}
+		r.hosts[host] = entry
+	}
+	r.mu.Unlock()
+	entry.once.Do(func() {
+		robots, err := r.fetch(host + robotsPath)
+		if err != nil {
+			r.bus <- ProblemEvent{Message: "robots.txt is unavailable", Context: struct {
+				Host  string
+				Error string
+			}{host, err.Error()}}
+		}
+		entry.robots = robots
+	})
+	return entry.robots
+}
+
+func (r *robotsChecker) fetch(location string) (*robotstxt.RobotsData, error) {
+	req, err := http.NewRequest(http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	if r.userAgent != "" {
+		req.Header.Set(userAgentHeader, r.userAgent)
+	}
+	authorize(r.credentials, req.URL, req.Header)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { unsafe.Ignore(resp.Body.Close()) }()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, robotsMaxSize))
+	if err != nil {
+		return nil, err
+	}
+	return robotstxt.FromStatusAndBytes(resp.StatusCode, body)
+}
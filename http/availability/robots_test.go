@@ -0,0 +1,166 @@
+package availability_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.octolab.org/unsafe"
+
+	"github.com/kamilsk/check/http/availability"
+)
+
+func TestCrawlerColly_respectRobots(t *testing.T) {
+	var private int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/robots.txt":
+			unsafe.DoSilent(io.WriteString(rw, "User-agent: test\nDisallow: /private\n\nUser-agent: *\nDisallow: /\n"))
+		case "/":
+			unsafe.DoSilent(io.WriteString(rw, `<!doctype html>
+<html lang="en">
+<body>
+<a href="/public">public</a>
+<a href="/private">private</a>
+<img src="/private.png">
+</body>
+</html>`))
+		case "/public":
+			rw.WriteHeader(http.StatusOK)
+		default:
+			atomic.AddInt32(&private, 1)
+			rw.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		config   availability.CrawlerConfig
+		skipped  map[string]string
+		requests int32
+	}{
+		{
+			"ignore robots",
+			availability.CrawlerConfig{UserAgent: "test/dev"},
+			map[string]string{},
+			2,
+		},
+		{
+			"respect robots",
+			availability.CrawlerConfig{UserAgent: "test/dev", RespectRobots: true},
+			map[string]string{
+				server.URL + "/private":     availability.RobotsSkipReason,
+				server.URL + "/private.png": availability.RobotsSkipReason,
+			},
+			0,
+		},
+	}
+	for _, test := range tests {
+		tc := test
+		t.Run(test.name, func(t *testing.T) {
+			atomic.StoreInt32(&private, 0)
+			skipped := make(map[string]string)
+			crawler := availability.CrawlerColly(tc.config)
+			events, err := collect(crawler, server.URL+"/")
+			assert.NoError(t, err)
+			for _, event := range events {
+				if e, is := event.(availability.SkipEvent); is {
+					skipped[e.Location] = e.Reason
+				}
+			}
+			assert.Equal(t, tc.skipped, skipped)
+			assert.Equal(t, tc.requests, atomic.LoadInt32(&private))
+		})
+	}
+
+	t.Run("unavailable robots.txt", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == "/robots.txt" {
+				conn, _, err := rw.(http.Hijacker).Hijack()
+				assert.NoError(t, err)
+				unsafe.Ignore(conn.Close())
+				return
+			}
+			rw.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		buf := bytes.NewBuffer(nil)
+		report := availability.NewReport(availability.CrawlerForSites(availability.CrawlerColly(
+			availability.CrawlerConfig{RespectRobots: true},
+		))).For([]string{server.URL + "/"}).Fill()
+		printer := availability.NewPrinter(
+			availability.FormatOutput(availability.JSONFormat),
+			availability.OutputForPrinting(buf),
+		)
+		assert.NoError(t, printer.For(report).Print())
+		sites, err := availability.LoadSites(buf)
+		assert.NoError(t, err)
+		assert.Len(t, sites, 1)
+		assert.Len(t, sites[0].Problems, 1)
+		assert.Equal(t, "robots.txt is unavailable", sites[0].Problems[0].Message)
+		context, is := sites[0].Problems[0].Context.(map[string]interface{})
+		assert.True(t, is)
+		assert.Equal(t, server.URL, context["Host"])
+		assert.Contains(t, context["Error"], "EOF")
+	})
+
+	t.Run("disallowed entry point", func(t *testing.T) {
+		bus := availability.NewReadableEventBus(8)
+		crawler := availability.CrawlerColly(availability.CrawlerConfig{UserAgent: "bot", RespectRobots: true})
+		assert.Error(t, crawler.Visit(server.URL+"/", bus))
+	})
+
+	t.Run("slow robots.txt of another host", func(t *testing.T) {
+		var once sync.Once
+		var waited int32
+		released, timeout := make(chan struct{}), time.After(2*time.Second)
+		external := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == "/robots.txt" {
+				select {
+				case <-released:
+				case <-timeout:
+					atomic.StoreInt32(&waited, 1)
+				}
+			}
+			rw.WriteHeader(http.StatusOK)
+		}))
+		defer external.Close()
+		site := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			type link = struct {
+				Href string
+				Text string
+			}
+			switch req.URL.Path {
+			case "/":
+				unsafe.Ignore(tpl.Execute(rw, []link{{Href: "/a", Text: "a"}, {Href: "/b", Text: "b"}}))
+			case "/a":
+				unsafe.Ignore(tpl.Execute(rw, []link{{Href: external.URL + "/", Text: "external"}}))
+			case "/b":
+				time.Sleep(100 * time.Millisecond)
+				unsafe.Ignore(tpl.Execute(rw, []link{{Href: "/c", Text: "c"}}))
+			case "/c":
+				once.Do(func() { close(released) })
+				rw.WriteHeader(http.StatusOK)
+			default:
+				rw.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer site.Close()
+
+		report := availability.NewReport(availability.CrawlerForSites(availability.CrawlerColly(
+			availability.CrawlerConfig{RespectRobots: true, Concurrency: 4},
+		))).For([]string{site.URL + "/"}).Fill()
+		for site := range report.Sites() {
+			assert.NoError(t, site.Error)
+		}
+		assert.Zero(t, atomic.LoadInt32(&waited), "pages of the website wait for robots.txt of another host")
+	})
+}
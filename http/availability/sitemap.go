@@ -0,0 +1,176 @@
+package availability
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.octolab.org/unsafe"
+
+	"github.com/kamilsk/check/errors"
+)
+
+const (
+	robotsPath       = "/robots.txt"
+	sitemapPath      = "/sitemap.xml"
+	sitemapDirective = "sitemap:"
+	userAgentHeader  = "User-Agent"
+	gzipMagicNumber  = "\x1f\x8b"
+
+	// sitemapLimit limits how many sitemaps are read.
+	sitemapLimit = 64
+	// sitemapNesting limits how deep sitemap indexes are read.
+	sitemapNesting = 3
+	// sitemapMaxSize limits a size of an uncompressed sitemap.
+	sitemapMaxSize = 50 << 20
+	// sitemapTimeout limits a duration of a sitemap request.
+	sitemapTimeout = 30 * time.Second
+)
+
+// sitemapReader collects locations of pages listed in sitemaps of a website.
+// Sitemaps are declared by robots.txt, /sitemap.xml is used as a fallback.
+// Sitemap index files and gzipped sitemaps are supported.
+type sitemapReader struct {
+	client      *http.Client
+	userAgent   string
+	credentials []Credential
+	visited     map[string]bool
+	found       bool
+	pages       []string
+	problems    []ProblemEvent
+}
+
+func newSitemapReader(config CrawlerConfig) *sitemapReader {
+	return &sitemapReader{
+		client:      &http.Client{Timeout: sitemapTimeout, Transport: config.transport},
+		userAgent:   config.UserAgent,
+		credentials: config.Credentials,
+		visited:     make(map[string]bool),
+	}
+}
+
+// read returns locations of pages listed in sitemaps of the website
+// and false if no sitemap is found.
+func (r *sitemapReader) read(base *url.URL) ([]string, bool) {
+	sitemaps := r.robots(base)
+	if len(sitemaps) == 0 {
+		sitemaps = []string{base.ResolveReference(&url.URL{Path: sitemapPath}).String()}
+	}
+	for _, sitemap := range sitemaps {
+		r.sitemap(sitemap, 1)
+	}
+	return r.pages, r.found
+}
+
+// robots returns locations of sitemaps declared by robots.txt.
+func (r *sitemapReader) robots(base *url.URL) []string {
+	body, err := r.fetch(base.ResolveReference(&url.URL{Path: robotsPath}).String())
+	if err != nil {
+		return nil
+	}
+	sitemaps := make([]string, 0, 1)
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) > len(sitemapDirective) && strings.EqualFold(line[:len(sitemapDirective)], sitemapDirective) {
+			if location := strings.TrimSpace(line[len(sitemapDirective):]); location != "" {
+				sitemaps = append(sitemaps, location)
+			}
+		}
+	}
+	return sitemaps
+}
+
+// sitemap reads a sitemap or a sitemap index by the location.
+func (r *sitemapReader) sitemap(location string, level int) {
+	if r.visited[location] || len(r.visited) >= sitemapLimit {
+		return
+	}
+	r.visited[location] = true
+	body, err := r.fetch(location)
+	if err != nil {
+		r.problem("sitemap is unavailable", location, err)
+		return
+	}
+	var document struct {
+		XMLName  xml.Name
+		URLs     []sitemapEntry `xml:"url"`
+		Sitemaps []sitemapEntry `xml:"sitemap"`
+	}
+	if err = xml.Unmarshal(body, &document); err != nil {
+		r.problem("sitemap is invalid", location, err)
+		return
+	}
+	switch document.XMLName.Local {
+	case "urlset":
+		r.found = true
+		for _, page := range document.URLs {
+			if loc := strings.TrimSpace(page.Loc); loc != "" {
+				r.pages = append(r.pages, loc)
+			}
+		}
+	case "sitemapindex":
+		if level >= sitemapNesting {
+			r.problem("sitemap index is too deep", location, errors.Errorf("nesting exceeds %d levels", sitemapNesting))
+			return
+		}
+		for _, sitemap := range document.Sitemaps {
+			if loc := strings.TrimSpace(sitemap.Loc); loc != "" {
+				r.sitemap(loc, level+1)
+			}
+		}
+	default:
+		r.problem("sitemap is invalid", location, errors.Errorf("unexpected root element %q", document.XMLName.Local))
+	}
+}
+
+// fetch returns a body of the response, it's decompressed if it's gzipped.
+func (r *sitemapReader) fetch(location string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	if r.userAgent != "" {
+		req.Header.Set(userAgentHeader, r.userAgent)
+	}
+	authorize(r.credentials, req.URL, req.Header)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { unsafe.Ignore(resp.Body.Close()) }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, sitemapMaxSize))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(body, []byte(gzipMagicNumber)) {
+		return body, nil
+	}
+	unzipped, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { unsafe.Ignore(unzipped.Close()) }()
+	return ioutil.ReadAll(io.LimitReader(unzipped, sitemapMaxSize))
+}
+
+type sitemapEntry struct {
+	Loc string `xml:"loc"`
+}
+
+func (r *sitemapReader) problem(message, location string, err error) {
+	r.problems = append(r.problems, ProblemEvent{Message: message, Context: struct {
+		Sitemap string
+		Error   string
+	}{location, err.Error()}})
+}
@@ -0,0 +1,154 @@
+package availability_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.octolab.org/unsafe"
+
+	"github.com/kamilsk/check/http/availability"
+)
+
+func TestCrawlerColly_sitemap(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/robots.txt":
+			unsafe.DoSilent(fmt.Fprintf(rw, "User-agent: *\nDisallow:\nSitemap: %s/sitemap-index.xml\n", server.URL))
+		case "/sitemap-index.xml":
+			unsafe.DoSilent(fmt.Fprintf(rw, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>%[1]s/sitemap-pages.xml.gz</loc></sitemap>
+  <sitemap><loc>%[1]s/sitemap-missing.xml</loc></sitemap>
+</sitemapindex>`, server.URL))
+		case "/sitemap-pages.xml.gz":
+			buf := bytes.NewBuffer(nil)
+			zip := gzip.NewWriter(buf)
+			unsafe.DoSilent(fmt.Fprintf(zip, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>%[1]s/</loc></url>
+  <url><loc>%[1]s/linked</loc></url>
+  <url><loc>%[1]s/orphan</loc></url>
+</urlset>`, server.URL))
+			unsafe.Ignore(zip.Close())
+			unsafe.DoSilent(io.Copy(rw, buf))
+		case "/":
+			unsafe.Ignore(tpl.Execute(rw, []struct {
+				Href string
+				Text string
+			}{
+				{Href: "/linked", Text: "linked"},
+				{Href: "/unlisted", Text: "unlisted"},
+				{Href: "/leaf", Text: "unlisted without links"},
+				{Href: "/missing", Text: "unlisted but missing"},
+			}))
+		case "/linked", "/unlisted", "/orphan":
+			unsafe.Ignore(tpl.Execute(rw, []struct {
+				Href string
+				Text string
+			}{{Href: "/", Text: "home"}}))
+		case "/leaf":
+			unsafe.Ignore(tpl.Execute(rw, nil))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	report := availability.NewReport(availability.CrawlerForSites(
+		availability.CrawlerColly(availability.CrawlerConfig{Sitemap: true}),
+	)).For([]string{server.URL + "/"}).Fill()
+	for site := range report.Sites() {
+		assert.NoError(t, site.Error)
+		assert.Len(t, site.Orphans, 1)
+		assert.Equal(t, server.URL+"/orphan", site.Orphans[0].Location)
+		assert.Equal(t, http.StatusOK, site.Orphans[0].StatusCode)
+		assert.Len(t, site.Unlisted, 2)
+		assert.Equal(t, server.URL+"/leaf", site.Unlisted[0].Location)
+		assert.Equal(t, server.URL+"/unlisted", site.Unlisted[1].Location)
+		assert.Len(t, site.Problems, 1)
+		assert.Equal(t, "sitemap is unavailable", site.Problems[0].Message)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	report = availability.NewReport(availability.CrawlerForSites(
+		availability.CrawlerColly(availability.CrawlerConfig{Sitemap: true}),
+	)).For([]string{server.URL + "/"}).Fill()
+	printer := availability.NewPrinter(
+		availability.FormatOutput(availability.JSONFormat),
+		availability.OutputForPrinting(buf),
+	)
+	assert.NoError(t, printer.For(report).Print())
+	sites, err := availability.LoadSites(buf)
+	assert.NoError(t, err)
+	assert.Len(t, sites, 1)
+	assert.Len(t, sites[0].Problems, 1)
+	assert.Equal(t, map[string]interface{}{
+		"Sitemap": server.URL + "/sitemap-missing.xml",
+		"Error":   "unexpected status code 404",
+	}, sites[0].Problems[0].Context)
+
+	deep := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		next := map[string]string{"/sitemap.xml": "/s2.xml", "/s2.xml": "/s3.xml", "/s3.xml": "/s4.xml"}
+		switch req.URL.Path {
+		case "/sitemap.xml", "/s2.xml", "/s3.xml":
+			unsafe.DoSilent(fmt.Fprintf(rw, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>http://%s%s</loc></sitemap>
+</sitemapindex>`, req.Host, next[req.URL.Path]))
+		case "/s4.xml":
+			unsafe.DoSilent(fmt.Fprintf(rw, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>http://%s/</loc></url>
+</urlset>`, req.Host))
+		case "/":
+			unsafe.Ignore(tpl.Execute(rw, nil))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer deep.Close()
+
+	report = availability.NewReport(availability.CrawlerForSites(
+		availability.CrawlerColly(availability.CrawlerConfig{Sitemap: true}),
+	)).For([]string{deep.URL + "/"}).Fill()
+	for site := range report.Sites() {
+		assert.NoError(t, site.Error)
+		assert.Len(t, site.Problems, 1)
+		assert.Equal(t, "sitemap index is too deep", site.Problems[0].Message)
+		assert.Equal(t, struct {
+			Sitemap string
+			Error   string
+		}{deep.URL + "/s3.xml", "nesting exceeds 3 levels"}, site.Problems[0].Context)
+	}
+}
+
+func TestCrawlerColly_withoutSitemap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		unsafe.Ignore(tpl.Execute(rw, []struct {
+			Href string
+			Text string
+		}{{Href: "/", Text: "home"}}))
+	}))
+	defer server.Close()
+
+	report := availability.NewReport(availability.CrawlerForSites(
+		availability.CrawlerColly(availability.CrawlerConfig{Sitemap: true}),
+	)).For([]string{server.URL + "/"}).Fill()
+	for site := range report.Sites() {
+		assert.NoError(t, site.Error)
+		assert.Empty(t, site.Orphans)
+		assert.Empty(t, site.Unlisted)
+		assert.Len(t, site.Problems, 1)
+	}
+}
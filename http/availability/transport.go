@@ -0,0 +1,220 @@
+package availability
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+
+	"github.com/kamilsk/check/errors"
+)
+
+const (
+	// defaultConnectTimeout limits a duration of establishing a connection
+	// if it's not specified, like the default transport does.
+	defaultConnectTimeout = 30 * time.Second
+	// keepAlive is a period of keep-alive probes of connections.
+	keepAlive = 30 * time.Second
+)
+
+// LoadCertificates reads PEM encoded certificates of authorities
+// and returns them in a pool with the system ones.
+func LoadCertificates(r io.Reader) (*x509.CertPool, error) {
+	blob, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read certificates")
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(blob) {
+		return nil, errors.Simple("no PEM encoded certificates found")
+	}
+	return pool, nil
+}
+
+// ParseResolve converts overrides of host addresses in the "host:port:address" format,
+// e.g. "www.example.com:443:10.0.0.1" or "www.example.com:443:[::1]",
+// into a map of addresses to dial instead of the "host:port" keys.
+func ParseResolve(values []string) (map[string]string, error) {
+	resolve := make(map[string]string, len(values))
+	for _, value := range values {
+		parts := strings.SplitN(value, ":", 3)
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, errors.Errorf("invalid resolve %q: expected host:port:address", value)
+		}
+		host, port, address := parts[0], parts[1], strings.Trim(parts[2], "[]")
+		if number, err := strconv.Atoi(port); err != nil || number < 1 || number > 65535 {
+			return nil, errors.Errorf("invalid port of resolve %q", value)
+		}
+		if net.ParseIP(address) == nil {
+			return nil, errors.Errorf("invalid address of resolve %q: IP is expected", value)
+		}
+		resolve[net.JoinHostPort(strings.ToLower(host), port)] = net.JoinHostPort(address, port)
+	}
+	return resolve, nil
+}
+
+// newTransport returns a transport configured by the timeouts, overrides of
+// host addresses, the proxy and the trusted certificates of the config.
+// Unspecified settings are the same as of the default transport.
+func newTransport(config CrawlerConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{Timeout: defaultConnectTimeout, KeepAlive: keepAlive}
+	if config.ConnectTimeout > 0 {
+		dialer.Timeout = config.ConnectTimeout
+	}
+	transport.DialContext = dialer.DialContext
+	if len(config.Resolve) > 0 {
+		transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+			if override, present := config.Resolve[strings.ToLower(address)]; present {
+				address = override
+			}
+			return dialer.DialContext(ctx, network, address)
+		}
+	}
+	if config.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = config.TLSHandshakeTimeout
+	}
+	transport.ResponseHeaderTimeout = config.ResponseTimeout
+	if config.Proxy != nil {
+		transport.Proxy = http.ProxyURL(config.Proxy)
+	}
+	if config.RootCAs != nil {
+		transport.TLSClientConfig = &tls.Config{RootCAs: config.RootCAs}
+	}
+	return transport
+}
+
+// withContext returns a transport which sends requests with the context,
+// so requests in flight are cancelled when it's done.
+func withContext(base http.RoundTripper, ctx context.Context) http.RoundTripper {
+	return &contextTransport{base: base, ctx: ctx}
+}
+
+type contextTransport struct {
+	base http.RoundTripper
+	ctx  context.Context
+}
+
+// RoundTrip sends the request with the context of the transport.
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(req.WithContext(t.ctx))
+}
+
+// NewThrottle returns limits of requests by the Concurrency and the PerHost of the config.
+// The limits are shared between all crawlers using the same throttle.
+func NewThrottle(config CrawlerConfig) *Throttle {
+	throttle := &Throttle{}
+	if config.Concurrency > 1 {
+		throttle.semaphore = make(chan struct{}, config.Concurrency)
+	}
+	if config.PerHost > 0 {
+		throttle.perHost = config.PerHost
+		throttle.hosts = make(map[string]chan struct{})
+	}
+	return throttle
+}
+
+// Throttle limits how many requests can be in flight at the same time
+// in total and to the same host.
+type Throttle struct {
+	semaphore chan struct{}
+	perHost   int
+
+	mu    sync.Mutex
+	hosts map[string]chan struct{}
+}
+
+// acquire waits for a slot of the host and a common one
+// and returns a function to release them.
+func (t *Throttle) acquire(host string) func() {
+	semaphore := t.host(host)
+	if semaphore != nil {
+		semaphore <- struct{}{}
+	}
+	if t.semaphore != nil {
+		t.semaphore <- struct{}{}
+	}
+	return func() {
+		if t.semaphore != nil {
+			<-t.semaphore
+		}
+		if semaphore != nil {
+			<-semaphore
+		}
+	}
+}
+
+func (t *Throttle) host(host string) chan struct{} {
+	if t.hosts == nil {
+		return nil
+	}
+	host = strings.ToLower(host)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	semaphore, present := t.hosts[host]
+	if !present {
+		semaphore = make(chan struct{}, t.perHost)
+		t.hosts[host] = semaphore
+	}
+	return semaphore
+}
+
+// limitConcurrency limits how many requests can be in flight at the same time
+// by all of the throttles, each of them is shared between all collectors using it.
+func limitConcurrency(base http.RoundTripper, throttles ...*Throttle) func(*colly.Collector) {
+	return func(c *colly.Collector) {
+		c.WithTransport(&limitedTransport{base: base, throttles: throttles})
+	}
+}
+
+type limitedTransport struct {
+	base      http.RoundTripper
+	throttles []*Throttle
+}
+
+// RoundTrip holds slots of the throttles until the response body is closed.
+// They are acquired in the same order, so a slot of a throttle is held
+// only while waiting for the next ones.
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	releases := make([]func(), 0, len(t.throttles))
+	for _, throttle := range t.throttles {
+		releases = append(releases, throttle.acquire(req.URL.Host))
+	}
+	release := func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+// Close closes the body and releases its slot only once.
+func (body *releasingBody) Close() error {
+	err := body.ReadCloser.Close()
+	body.once.Do(body.release)
+	return err
+}
@@ -0,0 +1,128 @@
+package availability_test
+
+import (
+	"bytes"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.octolab.org/unsafe"
+
+	"github.com/kamilsk/check/http/availability"
+)
+
+func TestCrawlerColly_transport(t *testing.T) {
+	visit := func(config availability.CrawlerConfig, entry string) (map[string]availability.ErrorEvent, error) {
+		events := make(map[string]availability.ErrorEvent)
+		collected, err := collect(availability.CrawlerColly(config), entry)
+		for _, event := range collected {
+			switch e := event.(type) {
+			case availability.ResponseEvent:
+				events[e.Location] = availability.ErrorEvent{StatusCode: e.StatusCode, Location: e.Location}
+			case availability.ErrorEvent:
+				events[e.Location] = e
+			}
+		}
+		return events, err
+	}
+
+	t.Run("response timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			time.Sleep(100 * time.Millisecond)
+			rw.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		events, err := visit(availability.CrawlerConfig{ResponseTimeout: 10 * time.Millisecond}, server.URL+"/")
+		assert.Error(t, err)
+		event := events[server.URL+"/"]
+		assert.True(t, event.Timeout)
+		assert.Equal(t, availability.TimeoutCategory, availability.Link{
+			Error:   event.Error,
+			Timeout: event.Timeout,
+		}.Category())
+	})
+
+	t.Run("proxy", func(t *testing.T) {
+		var requested string
+		proxy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			requested = req.URL.String()
+			rw.WriteHeader(http.StatusOK)
+		}))
+		defer proxy.Close()
+		u, err := url.Parse(proxy.URL)
+		assert.NoError(t, err)
+
+		events, err := visit(availability.CrawlerConfig{Proxy: u}, "http://staging.test/")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, events["http://staging.test/"].StatusCode)
+		assert.Equal(t, "http://staging.test/", requested)
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.URL.Path != "/" {
+				rw.WriteHeader(http.StatusOK)
+				return
+			}
+			unsafe.Ignore(tpl.Execute(rw, []struct {
+				Href string
+				Text string
+			}{
+				{Href: "/about", Text: "about"},
+			}))
+		}))
+		defer server.Close()
+		u, err := url.Parse(server.URL)
+		assert.NoError(t, err)
+		resolve, err := availability.ParseResolve([]string{"WWW.Example.test:" + u.Port() + ":" + u.Hostname()})
+		assert.NoError(t, err)
+
+		entry := "http://www.example.test:" + u.Port() + "/"
+		report := availability.NewReport(
+			availability.CrawlerForSites(availability.CrawlerColly(availability.CrawlerConfig{Resolve: resolve})),
+		).For([]string{entry}).Fill()
+		for site := range report.Sites() {
+			assert.NoError(t, site.Error)
+			assert.Equal(t, "www.example.test:"+u.Port(), site.Name)
+			assert.Len(t, site.Pages, 1)
+			assert.Len(t, site.Pages[0].Links, 1)
+			link := site.Pages[0].Links[0]
+			assert.Equal(t, entry+"about", link.Location)
+			assert.Equal(t, http.StatusOK, link.StatusCode)
+			assert.True(t, link.Internal)
+		}
+
+		for _, invalid := range []string{"www.example.test", "www.example.test:http:127.0.0.1", "www.example.test:80:localhost"} {
+			_, err := availability.ParseResolve([]string{invalid})
+			assert.Error(t, err)
+		}
+	})
+
+	t.Run("certificates", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		_, err := visit(availability.CrawlerConfig{}, server.URL+"/")
+		assert.Error(t, err)
+
+		pool, err := availability.LoadCertificates(bytes.NewReader(pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: server.Certificate().Raw,
+		})))
+		assert.NoError(t, err)
+		events, err := visit(availability.CrawlerConfig{RootCAs: pool}, server.URL+"/")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, events[server.URL+"/"].StatusCode)
+
+		_, err = availability.LoadCertificates(strings.NewReader("not a certificate"))
+		assert.Error(t, err)
+	})
+}
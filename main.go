@@ -60,7 +60,16 @@ func (app application) Run() {
 		Version: version,
 	})
 	if err = app.Cmd.Execute(); err != nil {
-		app.Shutdown(failed)
+		app.Shutdown(exitCode(err))
 	}
 	app.Shutdown(success)
 }
+
+// exitCode returns a code provided by the error or the failed code as a fallback.
+func exitCode(err error) int {
+	var coder interface{ ExitCode() int }
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+	return failed
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kamilsk/check/errors"
+	"github.com/kamilsk/check/http/availability"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		code int
+	}{
+		"simple error": {errors.Simple("unexpected"), failed},
+		"policy violation": {
+			&availability.PolicyViolation{Counts: map[string]int{availability.RedirectCategory: 1}},
+			3,
+		},
+		"the most severe category": {
+			&availability.PolicyViolation{Counts: map[string]int{
+				availability.RedirectCategory:    2,
+				availability.ClientErrorCategory: 1,
+				availability.TimeoutCategory:     1,
+			}},
+			9,
+		},
+		"wrapped policy violation": {
+			errors.Wrapf(&availability.PolicyViolation{Counts: map[string]int{availability.ServerErrorCategory: 1}}, "check"),
+			5,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.code, exitCode(test.err))
+		})
+	}
+}